@@ -8,10 +8,11 @@ import (
 	"io/ioutil"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
-	"unicode/utf8"
 
 	"github.com/vbauerster/mpb/v4/decor"
+	"github.com/vbauerster/mpb/v4/internal"
 )
 
 // Filler interface.
@@ -31,6 +32,7 @@ func (f FillerFunc) Fill(w io.Writer, width int, stat *decor.Statistics) {
 
 // Bar represents a progress Bar.
 type Bar struct {
+	id       int
 	priority int
 	index    int
 
@@ -46,8 +48,28 @@ type Bar struct {
 	done chan struct{}
 	// shutdown is closed from master Progress goroutine only
 	shutdown chan struct{}
+	// lastEvent is written from master Progress goroutine only
+	lastEvent *BarEvent
+	onRemove  func()
+	// group is set by BarGroup, see Progress.AbortGroup
+	group string
+	// pin state, see Pin, is written from master Progress goroutine only
+	pinSeq           int64
+	unpinnedPriority int
+	// rank is position assigned by WithSortBars, it's written from
+	// master Progress goroutine only
+	rank int
+	// sinkNotified is set once OnComplete or OnAbort is emitted, see
+	// WithEventSink. It's written from master Progress goroutine only
+	sinkNotified bool
 }
 
+// pinCounter orders pins, so the last pinned bar is on top
+var pinCounter int64
+
+// pinnedPriority is the top of priorities reserved for pinned bars
+const pinnedPriority = -1 << 30
+
 type (
 	bState struct {
 		filler             Filler
@@ -56,7 +78,10 @@ type (
 		width              int
 		total              int64
 		current            int64
+		items              int64
+		dynamic            bool
 		trimSpace          bool
+		minimal            bool
 		toComplete         bool
 		removeOnComplete   bool
 		barClearOnComplete bool
@@ -68,16 +93,58 @@ type (
 		bufP, bufB, bufA   *bytes.Buffer
 		bufE               *bytes.Buffer
 		panicMsg           string
+		startTime          time.Time
+		preRender          func(*decor.Statistics)
+		etaRefresh         time.Duration
+		etaAlpha           float64
+		decorSep           string
+		clock              func() time.Time
+		wrappedLines       int
+		overflow           OverflowMode
+		dimOnComplete      bool
+		spinner            Filler
+		spinnerWidth       int
+		aborted            bool
+		abortMsg           string
+		lineTerm           string
+		autoCompleteOnEOF  bool
+		noAutoComplete     bool
+		indent             int
+		userData           interface{}
+		finalLine          string
+		minWidth           int
+		err                error
+		completeThreshold  float64
+		totalFunc          func() int64
+		pinSeq             int64
+		// pending decorator changes, applied on next sync table build
+		syncPending []BarOption
+
+		// dynamic total auto increment, see BarAutoIncrementTotal
+		totalAutoIncrTrigger int64
+		totalAutoIncrBy      int64
+
+		// forced refresh on increment, see BarForceRefreshOnIncr
+		forceRefreshDelta int64
+		forceRefreshAcc   int64
+		forceRefreshCh    chan<- time.Time
 
 		// following options are assigned to the *Bar
 		priority   int
 		runningBar *Bar
+		onRemove   func()
+		group      string
 	}
 	bFrame struct {
 		rd               io.Reader
 		extendedLines    int
 		toShutdown       bool
 		removeOnComplete bool
+		syncPending      bool
+		pinSeq           int64
+		// event carries progress data of the frame, so flush path can
+		// feed hooks and outputs without extra round trip to the bar
+		event *BarEvent
 	}
 )
 
@@ -89,7 +156,8 @@ func newBar(
 	total int64,
 	options ...BarOption,
 ) *Bar {
-	if total <= 0 {
+	dynamic := total <= 0
+	if dynamic {
 		total = time.Now().Unix()
 	}
 
@@ -99,6 +167,9 @@ func newBar(
 		priority: id,
 		width:    width,
 		total:    total,
+		dynamic:  dynamic,
+		clock:    time.Now,
+		lineTerm: "\n",
 	}
 
 	for _, opt := range options {
@@ -107,6 +178,30 @@ func newBar(
 		}
 	}
 
+	if s.minimal {
+		s.pDecorators, s.aDecorators = nil, nil
+	}
+
+	if s.totalFunc != nil && !s.dynamic {
+		// total is unknown, until totalFunc has been resolved
+		s.dynamic = true
+		s.total = time.Now().Unix()
+	}
+
+	if s.dynamic && s.totalAutoIncrBy > 0 {
+		// placeholder is never reached, start from a real estimate
+		s.total = s.totalAutoIncrBy
+	}
+
+	if now := s.clock(); s.startTime.IsZero() || s.startTime.After(now) {
+		s.startTime = now
+	}
+	s.initDecorators(s.pDecorators)
+	s.initDecorators(s.aDecorators)
+	if c, ok := s.filler.(decor.Clocked); ok {
+		c.SetClock(s.clock)
+	}
+
 	s.bufP = bytes.NewBuffer(make([]byte, 0, width))
 	s.bufB = bytes.NewBuffer(make([]byte, 0, width))
 	s.bufA = bytes.NewBuffer(make([]byte, 0, width))
@@ -115,8 +210,12 @@ func newBar(
 	}
 
 	b := &Bar{
+		id:           s.id,
 		priority:     s.priority,
+		index:        -1,
 		runningBar:   s.runningBar,
+		onRemove:     s.onRemove,
+		group:        s.group,
 		operateState: make(chan func(*bState)),
 		bFrameCh:     make(chan *bFrame, 1),
 		syncTableCh:  make(chan [][]chan int),
@@ -134,22 +233,93 @@ func newBar(
 	return b
 }
 
+// newDeadBar returns a bar, which is never rendered. Its done channel
+// is closed from the start, so all methods fall back to cacheState and
+// return immediately. Dead bar reports itself completed, so loops like
+// `for !bar.Completed()` don't spin forever.
+func newDeadBar(filler Filler, total int64) *Bar {
+	b := &Bar{
+		id:    -1,
+		index: -1,
+		cacheState: &bState{
+			filler:     filler,
+			id:         -1,
+			total:      total,
+			toComplete: true,
+		},
+		done: make(chan struct{}),
+	}
+	close(b.done)
+	return b
+}
+
 // RemoveAllPrependers removes all prepend functions.
+// Like with PrependDecorators, change takes effect once container has
+// rebuilt its width sync matrix.
 func (b *Bar) RemoveAllPrependers() {
-	select {
-	case b.operateState <- func(s *bState) { s.pDecorators = nil }:
-	case <-b.done:
-	}
+	b.syncUpdate(func(s *bState) { s.pDecorators = nil })
 }
 
 // RemoveAllAppenders removes all append functions.
+// Like with AppendDecorators, change takes effect once container has
+// rebuilt its width sync matrix.
 func (b *Bar) RemoveAllAppenders() {
+	b.syncUpdate(func(s *bState) { s.aDecorators = nil })
+}
+
+// RemovePrepender removes i-th prepend decorator, counting from the
+// left. Index is checked once change is applied, out of range index is
+// ignored. Like with RemoveAllPrependers, change takes effect once
+// container has rebuilt its width sync matrix.
+func (b *Bar) RemovePrepender(i int) {
+	b.syncUpdate(func(s *bState) { s.pDecorators = removeDecorator(s.pDecorators, i) })
+}
+
+// RemoveAppender removes i-th append decorator, counting from the
+// left. Index is checked once change is applied, out of range index is
+// ignored. Like with RemoveAllAppenders, change takes effect once
+// container has rebuilt its width sync matrix.
+func (b *Bar) RemoveAppender(i int) {
+	b.syncUpdate(func(s *bState) { s.aDecorators = removeDecorator(s.aDecorators, i) })
+}
+
+func removeDecorator(decorators []decor.Decorator, i int) []decor.Decorator {
+	if i < 0 || i >= len(decorators) {
+		return decorators
+	}
+	return append(decorators[:i:i], decorators[i+1:]...)
+}
+
+// syncUpdate queues decorators change, which is applied on next sync
+// table build. Applying it right away would leave container's width
+// sync matrix stale, with renders blocked on abandoned channels.
+func (b *Bar) syncUpdate(opt BarOption) {
 	select {
-	case b.operateState <- func(s *bState) { s.aDecorators = nil }:
+	case b.operateState <- func(s *bState) { s.syncPending = append(s.syncPending, opt) }:
 	case <-b.done:
 	}
 }
 
+// AppendDecorators adds decorators to the bar's right side. Decorators
+// show up on the next but one render cycle, once container has
+// rebuilt its width sync matrix.
+func (b *Bar) AppendDecorators(appenders ...decor.Decorator) {
+	b.syncUpdate(func(s *bState) {
+		AppendDecorators(appenders...)(s)
+		s.initDecorators(appenders)
+	})
+}
+
+// PrependDecorators adds decorators to the bar's left side. Decorators
+// show up on the next but one render cycle, once container has
+// rebuilt its width sync matrix.
+func (b *Bar) PrependDecorators(prependers ...decor.Decorator) {
+	b.syncUpdate(func(s *bState) {
+		PrependDecorators(prependers...)(s)
+		s.initDecorators(prependers)
+	})
+}
+
 // ProxyReader wraps r with metrics required for progress tracking.
 func (b *Bar) ProxyReader(r io.Reader) io.ReadCloser {
 	if r == nil {
@@ -162,6 +332,16 @@ func (b *Bar) ProxyReader(r io.Reader) io.ReadCloser {
 	return &proxyReader{rc, b, time.Now()}
 }
 
+// ProxyReaderContext is like ProxyReader, but returned reader's Read
+// method returns ctx.Err() once ctx is done, breaking a copy loop.
+// Consider Progress.Abort, to get rid of the bar afterwards.
+func (b *Bar) ProxyReaderContext(ctx context.Context, r io.Reader) io.ReadCloser {
+	if ctx == nil {
+		panic("expect context.Context, got nil")
+	}
+	return &ctxProxyReader{b.ProxyReader(r).(*proxyReader), ctx}
+}
+
 // ID returs id of the bar.
 func (b *Bar) ID() int {
 	select {
@@ -172,6 +352,19 @@ func (b *Bar) ID() int {
 	}
 }
 
+// Filler returns filler the bar renders with. Returned filler must
+// not be mutated concurrently with rendering, consider a method which
+// goes through the bar, like SetRefill, instead.
+func (b *Bar) Filler() Filler {
+	result := make(chan Filler, 1)
+	select {
+	case b.operateState <- func(s *bState) { result <- s.filler }:
+		return <-result
+	case <-b.done:
+		return b.cacheState.filler
+	}
+}
+
 // Current returns bar's current number, in other words sum of all increments.
 func (b *Bar) Current() int64 {
 	select {
@@ -182,17 +375,65 @@ func (b *Bar) Current() int64 {
 	}
 }
 
-// SetTotal sets total dynamically.
+// Percent returns bar's progress in percents, zero if total is unknown,
+// i.e. zero or dynamic.
+func (b *Bar) Percent() float64 {
+	result := make(chan float64, 1)
+	select {
+	case b.operateState <- func(s *bState) { result <- s.percent() }:
+		return <-result
+	case <-b.done:
+		return b.cacheState.percent()
+	}
+}
+
+// Rescale sets total, adjusting current proportionally, so percentage
+// stays the same, when total estimate is revised. Non positive total
+// is ignored. Like SetTotal, it reports whether bar is still running.
+func (b *Bar) Rescale(total int64) bool {
+	select {
+	case b.operateState <- func(s *bState) {
+		if total <= 0 || s.toComplete {
+			return
+		}
+		s.current = int64(float64(s.current) * float64(total) / float64(s.total))
+		s.total = total
+	}:
+		return true
+	case <-b.done:
+		return false
+	}
+}
+
+// IsDynamic reports whether bar's total is unknown yet, i.e. bar has
+// been created with non positive total and SetTotal hasn't been called
+// with final flag since.
+func (b *Bar) IsDynamic() bool {
+	result := make(chan bool, 1)
+	select {
+	case b.operateState <- func(s *bState) { result <- s.dynamic }:
+		return <-result
+	case <-b.done:
+		return b.cacheState.dynamic
+	}
+}
+
+// SetTotal sets total dynamically, current is left as is, so
+// percentage jumps, if total changes notably. Consider Rescale, if
+// it's undesirable.
 // Set final to true, when total is known, it will trigger bar complete event.
+// Zero total is ignored, unless final is set, then bar completes right
+// away as an empty task, i.e. with 0/0 progress.
 func (b *Bar) SetTotal(total int64, final bool) bool {
 	select {
 	case b.operateState <- func(s *bState) {
-		if total > 0 {
+		if total > 0 || total == 0 && final {
 			s.total = total
 		}
 		if final {
 			s.current = s.total
 			s.toComplete = true
+			s.dynamic = false
 		}
 	}:
 		return true
@@ -203,10 +444,27 @@ func (b *Bar) SetTotal(total int64, final bool) bool {
 
 // SetRefill sets refill, if supported by underlying Filler.
 func (b *Bar) SetRefill(upto int) {
-	b.operateState <- func(s *bState) {
+	select {
+	case b.operateState <- func(s *bState) {
 		if f, ok := s.filler.(interface{ SetRefill(int) }); ok {
 			f.SetRefill(upto)
 		}
+	}:
+	case <-b.done:
+	}
+}
+
+// ResumeFillPercent is like SetRefill, but accepts percentage of total
+// already filled, clamped to [0, 100] range, and rune to refill with.
+// Refill follows total, if it changes afterwards.
+func (b *Bar) ResumeFillPercent(r rune, pct float64) {
+	select {
+	case b.operateState <- func(s *bState) {
+		if f, ok := s.filler.(interface{ SetRefillPercent(rune, float64) }); ok {
+			f.SetRefillPercent(r, pct)
+		}
+	}:
+	case <-b.done:
 	}
 }
 
@@ -219,28 +477,137 @@ func (b *Bar) Increment() {
 // wdd is optional work duration i.e. time.Since(start), which expected
 // to be provided, if any ewma based decorator is used.
 func (b *Bar) IncrBy(n int, wdd ...time.Duration) {
+	b.IncrInt64(int64(n), wdd...)
+}
+
+// IncrByDuration increments progress bar by amount of n, which took dur
+// of actual work, e.g. measured time of a buffered read. It's the same
+// as IncrBy(n, dur), but makes explicit, that ewma based decorators
+// are fed with dur rather than wall clock time between calls.
+func (b *Bar) IncrByDuration(n int, dur time.Duration) {
+	b.IncrInt64(int64(n), dur)
+}
+
+// IncrInt64 increments progress bar by amount of n. Use it instead of
+// IncrBy, if n may not fit into int on 32-bit platforms. Increments
+// after completion are ignored, unless bar is dynamic.
+// wdd is optional work duration i.e. time.Since(start), which expected
+// to be provided, if any ewma based decorator is used.
+func (b *Bar) IncrInt64(n int64, wdd ...time.Duration) {
+	select {
+	case b.operateState <- func(s *bState) { s.incrBy(n, wdd...) }:
+	case <-b.done:
+	}
+}
+
+// IncrWeighted increments progress bar by amount of weight, while
+// counting items separately, so ItemRate like decorators can display
+// throughput independent of weighted progress. wdd is the same as of
+// IncrBy.
+func (b *Bar) IncrWeighted(items int, weight int64, wdd ...time.Duration) {
 	select {
 	case b.operateState <- func(s *bState) {
-		s.current += int64(n)
-		if s.current >= s.total {
-			s.current = s.total
-			s.toComplete = true
-		}
-		for _, ar := range s.amountReceivers {
-			ar.NextAmount(n, wdd...)
+		if s.incrBy(weight, wdd...) {
+			s.items += int64(items)
 		}
 	}:
 	case <-b.done:
 	}
 }
 
+// ResetETA makes ETA decorators drop their estimate and start over,
+// see decor.ETAResetter. Call it after a major state change, like
+// rollback on retry, which renders past measurements irrelevant.
+func (b *Bar) ResetETA() {
+	select {
+	case b.operateState <- func(s *bState) { s.resetETA() }:
+	case <-b.done:
+	}
+}
+
+// Pin moves the bar to the top, until Unpin is called, e.g. to keep
+// a focused bar in sight. If more bars are pinned, the last pinned one
+// is on top. Change takes effect on the next but one render cycle.
+// While WithSortBars is on, priority only breaks ties of sort order,
+// so a pinned bar is on top of its equals only. Pin has no effect,
+// while WithBarComparator is on.
+func (b *Bar) Pin() {
+	seq := atomic.AddInt64(&pinCounter, 1)
+	select {
+	case b.operateState <- func(s *bState) { s.pinSeq = seq }:
+	case <-b.done:
+	}
+}
+
+// Unpin restores priority, the bar had before Pin was called, or the
+// one set by UpdateBarPriority while it was pinned.
+func (b *Bar) Unpin() {
+	select {
+	case b.operateState <- func(s *bState) { s.pinSeq = 0 }:
+	case <-b.done:
+	}
+}
+
+// applyPin updates priority according to pin state of the frame. It's
+// called from master Progress goroutine, while the bar isn't in heap.
+func (b *Bar) applyPin(pinSeq int64) {
+	if pinSeq == b.pinSeq {
+		return
+	}
+	if b.pinSeq == 0 {
+		b.unpinnedPriority = b.priority
+	}
+	if pinSeq == 0 {
+		b.priority = b.unpinnedPriority
+	} else {
+		b.priority = pinnedPriority - int(pinSeq)
+	}
+	b.pinSeq = pinSeq
+}
+
+// SetError attaches err to the bar, so decorators, like decor.OnError,
+// can display it. Nil err clears previously set one. Bar keeps running,
+// use Progress.Abort to stop it as well.
+func (b *Bar) SetError(err error) {
+	select {
+	case b.operateState <- func(s *bState) { s.err = err }:
+	case <-b.done:
+	}
+}
+
+// Err returns error set by SetError, if any.
+func (b *Bar) Err() error {
+	result := make(chan error, 1)
+	select {
+	case b.operateState <- func(s *bState) { result <- s.err }:
+		return <-result
+	case <-b.done:
+		return b.cacheState.err
+	}
+}
+
+// FinalLine returns text of the bar's last rendered line, as user has
+// seen it, without line terminator and extender lines. It's available
+// once the bar has completed and shut down, empty string is returned
+// before that.
+func (b *Bar) FinalLine() string {
+	select {
+	case <-b.done:
+		return b.cacheState.finalLine
+	default:
+		return ""
+	}
+}
+
 // Completed reports whether the bar is in completed state.
 func (b *Bar) Completed() bool {
-	// omit select here, because primary usage of the method is for loop
-	// condition, like for !bar.Completed() {...} so when toComplete=true
-	// it is called once (at which time, the bar is still alive), then
-	// quits the loop and never suppose to be called afterwards.
-	return <-b.completed
+	select {
+	case v := <-b.completed:
+		return v
+	case <-b.done:
+		// cacheState is written before done is closed
+		return b.cacheState.toComplete
+	}
 }
 
 func (b *Bar) wSyncTable() [][]chan int {
@@ -275,74 +642,244 @@ func (b *Bar) serve(ctx context.Context, wg *sync.WaitGroup, s *bState) {
 	}
 }
 
-func (b *Bar) render(debugOut io.Writer, tw int) {
+// render sends bar's frame to bFrameCh. If peek is true, frame is
+// rendered for a snapshot only, so completion isn't marked as flushed.
+func (b *Bar) render(debugOut io.Writer, tw int, peek bool) {
 	select {
 	case b.operateState <- func(s *bState) {
+		if s.totalFunc != nil {
+			go b.resolveTotal(s.totalFunc)
+			s.totalFunc = nil
+		}
 		defer func() {
 			// recovering if user defined decorator panics for example
 			if p := recover(); p != nil {
 				s.panicMsg = fmt.Sprintf("panic: %v", p)
 				fmt.Fprintf(debugOut, "%s %s bar id %02d %v\n", "[mpb]", time.Now(), s.id, s.panicMsg)
 				b.bFrameCh <- &bFrame{
-					rd:         strings.NewReader(fmt.Sprintf(fmt.Sprintf("%%.%ds\n", tw), s.panicMsg)),
-					toShutdown: true,
+					rd:         strings.NewReader(internal.Truncate(s.panicMsg, tw) + s.lineTerm),
+					event:      newBarEvent(s),
+					toShutdown: !peek,
 				}
 			}
 		}()
 		r := s.draw(tw)
-		var extendedLines int
+		if s.toComplete && !peek {
+			// completed bar renders a few frames only, the last one is kept
+			line, _ := ioutil.ReadAll(r)
+			s.finalLine = strings.TrimSuffix(string(line), s.lineTerm)
+			r = bytes.NewReader(line)
+		}
+		extendedLines := s.wrappedLines
 		if s.extender != nil {
 			s.extender.Fill(s.bufE, tw, newStatistics(s))
-			extendedLines = countLines(s.bufE.Bytes())
+			extendedLines += countLines(s.bufE.Bytes())
 			r = io.MultiReader(r, s.bufE)
 		}
 		b.bFrameCh <- &bFrame{
 			rd:               r,
+			event:            newBarEvent(s),
 			extendedLines:    extendedLines,
-			toShutdown:       s.toComplete && !s.completeFlushed,
+			toShutdown:       s.toComplete && !s.completeFlushed && !peek,
 			removeOnComplete: s.removeOnComplete,
+			syncPending:      len(s.syncPending) != 0,
+			pinSeq:           s.pinSeq,
+		}
+		if !peek {
+			s.completeFlushed = s.toComplete
 		}
-		s.completeFlushed = s.toComplete
 	}:
 	case <-b.done:
 		s := b.cacheState
 		r := s.draw(tw)
-		var extendedLines int
+		extendedLines := s.wrappedLines
 		if s.extender != nil {
 			s.extender.Fill(s.bufE, tw, newStatistics(s))
-			extendedLines = countLines(s.bufE.Bytes())
+			extendedLines += countLines(s.bufE.Bytes())
 			r = io.MultiReader(r, s.bufE)
 		}
 		b.bFrameCh <- &bFrame{
 			rd:            r,
+			event:         newBarEvent(s),
 			extendedLines: extendedLines,
 		}
 	}
 }
 
+// markAborted marks incomplete bar as aborted, so BarAbortMessage is
+// rendered instead of the filler.
+func (b *Bar) markAborted() {
+	select {
+	case b.operateState <- func(s *bState) { s.aborted = !s.toComplete }:
+	case <-b.done:
+	}
+}
+
+// resolveTotal sets total to value returned by fn, see BarTotalFunc.
+// It's called in its own goroutine, so slow fn doesn't block rendering.
+func (b *Bar) resolveTotal(fn func() int64) {
+	total := fn()
+	if total <= 0 {
+		return
+	}
+	select {
+	case b.operateState <- func(s *bState) {
+		if !s.dynamic {
+			// SetTotal with final flag has been called meanwhile
+			return
+		}
+		s.total = total
+		s.dynamic = false
+		if s.reachedTotal() && !s.noAutoComplete {
+			s.current = s.total
+			s.toComplete = true
+		}
+	}:
+	case <-b.done:
+	}
+}
+
+// completeOnEOF is called by proxy reader on io.EOF, it snaps total to
+// current, if BarAutoCompleteOnEOF is set.
+func (b *Bar) completeOnEOF() {
+	select {
+	case b.operateState <- func(s *bState) {
+		if !s.autoCompleteOnEOF || s.toComplete {
+			return
+		}
+		if s.current > 0 {
+			s.total = s.current
+		}
+		s.toComplete = true
+		s.dynamic = false
+	}:
+	case <-b.done:
+	}
+}
+
+func (b *Bar) removed() {
+	if b.onRemove != nil {
+		b.onRemove()
+	}
+}
+
+// incrBy reports whether increment has been applied. Increments after
+// completion are ignored, unless bar is dynamic.
+func (s *bState) incrBy(n int64, wdd ...time.Duration) bool {
+	if s.toComplete && !s.dynamic {
+		return false
+	}
+	s.current += n
+	if s.totalAutoIncrBy > 0 && s.dynamic && !s.toComplete &&
+		internal.Percentage(s.total, s.current, 100) >= s.totalAutoIncrTrigger {
+		s.total += s.totalAutoIncrBy
+		if s.total <= s.current {
+			// increment has jumped over the estimate
+			s.total = s.current + s.totalAutoIncrBy
+		}
+	}
+	var grown bool
+	if s.current > s.total {
+		switch s.overflow {
+		case OverflowGrowTotal:
+			s.total = s.current
+			grown = true
+		case OverflowError:
+			if s.panicMsg == "" {
+				s.panicMsg = fmt.Sprintf("overflow: current %d exceeds total %d", s.current, s.total)
+			}
+		}
+	}
+	if s.reachedTotal() && !s.noAutoComplete && !grown {
+		s.current = s.total
+		s.toComplete = true
+	}
+	for _, ar := range s.amountReceivers {
+		ar.NextAmount(n, wdd...)
+	}
+	if s.forceRefreshDelta > 0 {
+		s.forceRefreshAcc += n
+		if s.forceRefreshAcc >= s.forceRefreshDelta || s.toComplete {
+			select {
+			case s.forceRefreshCh <- time.Now():
+				s.forceRefreshAcc = 0
+			default:
+				// refresh is on its way already, coalesce
+			}
+		}
+	}
+	return true
+}
+
+func (s *bState) percent() float64 {
+	if s.dynamic {
+		// total is just a placeholder
+		return 0
+	}
+	return internal.PercentageRaw(s.total, s.current, 100)
+}
+
+// reachedTotal reports whether current is at total, or close enough
+// according to BarCompleteThreshold. Dynamic total is never close
+// enough, as it's just an estimate.
+func (s *bState) reachedTotal() bool {
+	if s.current >= s.total {
+		return true
+	}
+	return s.completeThreshold > 0 && !s.dynamic &&
+		internal.PercentageRaw(s.total, s.current, 100) >= s.completeThreshold
+}
+
 func (s *bState) draw(termWidth int) io.Reader {
+	s.wrappedLines = 0
+
 	if s.panicMsg != "" {
-		return strings.NewReader(fmt.Sprintf(fmt.Sprintf("%%.%ds\n", termWidth), s.panicMsg))
+		s.syncIdle()
+		return strings.NewReader(internal.Truncate(s.panicMsg, termWidth) + s.lineTerm)
 	}
 
 	stat := newStatistics(s)
 
-	for _, d := range s.pDecorators {
+	if s.preRender != nil {
+		s.preRender(stat)
+	}
+
+	if s.minimal {
+		// filler only, see BarMinimal
+		if s.width < termWidth {
+			termWidth = s.width
+		}
+		s.filler.Fill(s.bufB, termWidth, stat)
+		return s.line(s.bufB)
+	}
+
+	if s.indent > 0 {
+		s.bufP.WriteString(strings.Repeat(" ", s.indent))
+	}
+
+	for i, d := range s.pDecorators {
+		if i > 0 {
+			s.bufP.WriteString(s.decorSep)
+		}
 		s.bufP.WriteString(d.Decor(stat))
 	}
 
-	for _, d := range s.aDecorators {
+	for i, d := range s.aDecorators {
+		if i > 0 {
+			s.bufA.WriteString(s.decorSep)
+		}
 		s.bufA.WriteString(d.Decor(stat))
 	}
 
+	prependCount := internal.BytesWidth(s.bufP.Bytes())
+	appendCount := internal.BytesWidth(s.bufA.Bytes())
+
 	if s.barClearOnComplete && s.completeFlushed {
-		s.bufA.WriteByte('\n')
-		return io.MultiReader(s.bufP, s.bufA)
+		s.wrappedLines = wrappedLines(prependCount+appendCount, termWidth)
+		return s.line(s.bufP, s.bufA)
 	}
 
-	prependCount := utf8.RuneCount(s.bufP.Bytes())
-	appendCount := utf8.RuneCount(s.bufA.Bytes())
-
+	lineWidth := termWidth
 	if !s.trimSpace {
 		// reserve space for edge spaces
 		termWidth -= 2
@@ -353,17 +890,72 @@ func (s *bState) draw(termWidth int) io.Reader {
 	if prependCount+s.width+appendCount > termWidth {
 		calcWidth = termWidth - prependCount - appendCount
 	}
-	s.filler.Fill(s.bufB, calcWidth, stat)
+	// unset min width keeps filler, however narrow it is
+	tooNarrow := s.minWidth > 0 && calcWidth < s.minWidth
+	switch {
+	case tooNarrow:
+		// too narrow, see WithMinBarWidth, the leading space, if any,
+		// is kept as a separator of decorators
+	case s.aborted && s.abortMsg != "":
+		s.bufB.WriteString(internal.Truncate(s.abortMsg, calcWidth))
+	default:
+		if s.spinner != nil && calcWidth > s.spinnerWidth {
+			s.spinner.Fill(s.bufB, s.spinnerWidth, stat)
+			calcWidth -= s.spinnerWidth
+		}
+		s.filler.Fill(s.bufB, calcWidth, stat)
+	}
 
-	if !s.trimSpace {
+	if !s.trimSpace && !tooNarrow {
 		s.bufB.WriteByte(' ')
 	}
 
-	s.bufA.WriteByte('\n')
-	return io.MultiReader(s.bufP, s.bufB, s.bufA)
+	// decorators may not fit, in which case terminal wraps the line
+	barCount := internal.BytesWidth(s.bufB.Bytes())
+	s.wrappedLines = wrappedLines(prependCount+barCount+appendCount, lineWidth)
+
+	return s.line(s.bufP, s.bufB, s.bufA)
+}
+
+// line terminates bar line, which is made of provided sections. SGR
+// sequences of BarDimOnComplete take no space on screen, so they're
+// added after all width calculations are done.
+func (s *bState) line(sections ...*bytes.Buffer) io.Reader {
+	dim := s.dimOnComplete && s.completeFlushed
+	rr := make([]io.Reader, 0, len(sections)+1)
+	if dim {
+		rr = append(rr, strings.NewReader("\x1b[2m"))
+	}
+	for _, b := range sections {
+		rr = append(rr, b)
+	}
+	last := sections[len(sections)-1]
+	if dim {
+		last.WriteString("\x1b[0m")
+	}
+	last.WriteString(s.lineTerm)
+	return io.MultiReader(rr...)
+}
+
+// wrappedLines returns count of extra lines, the line of n runes
+// takes, if terminal wraps it at width.
+func wrappedLines(n, width int) int {
+	if width <= 0 || n <= width {
+		return 0
+	}
+	return (n - 1) / width
 }
 
 func (s *bState) wSyncTable() [][]chan int {
+	for _, opt := range s.syncPending {
+		opt(s)
+	}
+	s.syncPending = nil
+	if s.minimal {
+		// never rendered, so must not take part in width sync
+		s.pDecorators, s.aDecorators = nil, nil
+	}
+
 	columns := make([]chan int, 0, len(s.pDecorators)+len(s.aDecorators))
 	var pCount int
 	for _, d := range s.pDecorators {
@@ -385,10 +977,59 @@ func (s *bState) wSyncTable() [][]chan int {
 	return table
 }
 
+// syncIdle takes part in width sync with zero width, as decorators
+// aren't rendered. Otherwise bars sharing a synced column would wait
+// for this one forever.
+func (s *bState) syncIdle() {
+	for _, decorators := range [...][]decor.Decorator{s.pDecorators, s.aDecorators} {
+		for _, d := range decorators {
+			if ch, ok := d.Sync(); ok {
+				ch <- 0
+				<-ch
+			}
+		}
+	}
+}
+
+func (s *bState) resetETA() {
+	for _, decorators := range [...][]decor.Decorator{s.pDecorators, s.aDecorators} {
+		for _, d := range decorators {
+			if er, ok := d.(decor.ETAResetter); ok {
+				er.ResetETA()
+			}
+		}
+	}
+}
+
+// initDecorators applies bar wide settings, i.e. ETA refresh interval,
+// EWMA alpha, clock and start time, to decorators. It's called on
+// construction and for decorators added afterwards.
+func (s *bState) initDecorators(decorators []decor.Decorator) {
+	for _, d := range decorators {
+		if rl, ok := d.(decor.RefreshLimiter); ok && s.etaRefresh > 0 {
+			rl.SetRefreshInterval(s.etaRefresh)
+		}
+		if es, ok := d.(decor.EwmaAlphaSetter); ok && s.etaAlpha > 0 {
+			es.SetEwmaAlpha(s.etaAlpha)
+		}
+		// clock goes first, as setting it restarts time measurement
+		if c, ok := d.(decor.Clocked); ok {
+			c.SetClock(s.clock)
+		}
+		if ss, ok := d.(decor.StartTimeSetter); ok {
+			ss.SetStartTime(s.startTime)
+		}
+	}
+}
+
 func newStatistics(s *bState) *decor.Statistics {
 	return &decor.Statistics{
 		ID:        s.id,
 		Completed: s.completeFlushed,
+		Dynamic:   s.dynamic,
+		Items:     s.items,
+		UserData:  s.userData,
+		Err:       s.err,
 		Total:     s.total,
 		Current:   s.current,
 	}
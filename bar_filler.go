@@ -3,6 +3,7 @@ package mpb
 import (
 	"bytes"
 	"io"
+	"time"
 	"unicode/utf8"
 
 	"github.com/vbauerster/mpb/v4/decor"
@@ -20,9 +21,31 @@ const (
 
 var defaultBarStyle = "[=>-]+"
 
+// smoothTips are eighth blocks, indexed by eighths of a cell filled.
+// Zero index is never used, rEmpty is rendered instead.
+var smoothTips = [...]string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
 type barFiller struct {
-	format [][]byte
-	rup    int
+	format     [][]byte
+	cfill      []byte
+	rup        int
+	rupPercent float64
+	smoothTip  bool
+	noTip      bool
+	tipAnim    *tipAnimation
+}
+
+// tipAnimation cycles tip frames, based on time elapsed since start.
+type tipAnimation struct {
+	frames    [][]byte
+	interval  time.Duration
+	clock     func() time.Time
+	startTime time.Time
+}
+
+func (a *tipAnimation) frame() []byte {
+	n := int(a.clock().Sub(a.startTime) / a.interval)
+	return a.frames[n%len(a.frames)]
 }
 
 func newDefaultBarFiller() Filler {
@@ -33,6 +56,15 @@ func newDefaultBarFiller() Filler {
 	return bf
 }
 
+// SetClock is implementation of decor.Clocked interface, so tip
+// animation follows bar's clock, see BarClock.
+func (s *barFiller) SetClock(now func() time.Time) {
+	if s.tipAnim != nil {
+		s.tipAnim.clock = now
+		s.tipAnim.startTime = now()
+	}
+}
+
 func (s *barFiller) setStyle(style string) {
 	if !utf8.ValidString(style) {
 		style = defaultBarStyle
@@ -46,32 +78,64 @@ func (s *barFiller) setStyle(style string) {
 
 func (s *barFiller) Fill(w io.Writer, width int, stat *decor.Statistics) {
 
+	if width <= 0 {
+		return
+	} else if width < 3 {
+		// too narrow for brackets with any cell in between, so just
+		// fill cells according to percentage
+		cwidth := int(internal.Percentage(stat.Total, stat.Current, int64(width)))
+		if cwidth > width {
+			cwidth = width
+		}
+		b := bytes.Repeat(s.format[rFill], cwidth)
+		w.Write(append(b, bytes.Repeat(s.format[rEmpty], width-cwidth)...))
+		return
+	}
+
 	b := s.format[rLeft]
 
 	// don't count rLeft and rRight [brackets]
 	width -= 2
 
-	if width < 2 {
-		return
-	} else if width == 2 {
-		w.Write(append(b, s.format[rRight]...))
-		return
+	if s.smoothTip && !stat.Completed {
+		if exact := internal.PercentageRaw(stat.Total, stat.Current, int64(width)); exact < float64(width) {
+			w.Write(append(s.fillSmooth(b, width, exact, stat.Total), s.format[rRight]...))
+			return
+		}
 	}
 
 	cwidth := internal.Percentage(stat.Total, stat.Current, int64(width))
+	if cwidth > int64(width) || stat.Total == 0 && stat.Completed {
+		// current is past total, see BarNoAutoComplete, or the bar is
+		// a completed empty task
+		cwidth = int64(width)
+	}
+
+	fill := s.format[rFill]
+	if stat.Completed && s.cfill != nil {
+		fill = s.cfill
+	}
 
-	if s.rup > 0 {
-		rwidth := internal.Percentage(stat.Total, int64(s.rup), int64(width))
+	if rup := s.refillUpto(stat.Total); rup > 0 {
+		rwidth := internal.Percentage(stat.Total, rup, int64(width))
+		if rwidth > cwidth {
+			// refill point is ahead of current, e.g. total has grown
+			rwidth = cwidth
+		}
 		b = append(b, bytes.Repeat(s.format[rRefill], int(rwidth))...)
 		rest := cwidth - rwidth
-		b = append(b, bytes.Repeat(s.format[rFill], int(rest))...)
+		b = append(b, bytes.Repeat(fill, int(rest))...)
 	} else {
-		b = append(b, bytes.Repeat(s.format[rFill], int(cwidth))...)
+		b = append(b, bytes.Repeat(fill, int(cwidth))...)
 	}
 
-	if cwidth < int64(width) && cwidth > 0 {
+	if cwidth < int64(width) && cwidth > 0 && !s.noTip {
+		tip := s.format[rTip]
+		if s.tipAnim != nil {
+			tip = s.tipAnim.frame()
+		}
 		_, size := utf8.DecodeLastRune(b)
-		b = append(b[:len(b)-size], s.format[rTip]...)
+		b = append(b[:len(b)-size], tip...)
 	}
 
 	rest := int64(width) - cwidth
@@ -79,6 +143,57 @@ func (s *barFiller) Fill(w io.Writer, width int, stat *decor.Statistics) {
 	w.Write(append(b, s.format[rRight]...))
 }
 
+// fillSmooth fills completed cells by truncated exact width and then
+// renders fractional part of the last cell as an eighth block tip.
+func (s *barFiller) fillSmooth(b []byte, width int, exact float64, total int64) []byte {
+	if exact < 0 {
+		exact = 0
+	}
+	cwidth := int(exact)
+
+	if rup := s.refillUpto(total); rup > 0 {
+		rwidth := int(internal.PercentageRaw(total, rup, int64(width)))
+		if rwidth > cwidth {
+			rwidth = cwidth
+		}
+		b = append(b, bytes.Repeat(s.format[rRefill], rwidth)...)
+		b = append(b, bytes.Repeat(s.format[rFill], cwidth-rwidth)...)
+	} else {
+		b = append(b, bytes.Repeat(s.format[rFill], cwidth)...)
+	}
+
+	if eighths := int((exact - float64(cwidth)) * 8); eighths > 0 {
+		b = append(b, smoothTips[eighths]...)
+	} else {
+		b = append(b, s.format[rEmpty]...)
+	}
+
+	return append(b, bytes.Repeat(s.format[rEmpty], width-cwidth-1)...)
+}
+
 func (s *barFiller) SetRefill(upto int) {
 	s.rup = upto
+	s.rupPercent = 0
+}
+
+func (s *barFiller) SetRefillPercent(r rune, pct float64) {
+	if pct < 0 {
+		pct = 0
+	} else if pct > 100 {
+		pct = 100
+	}
+	if utf8.ValidRune(r) {
+		s.format[rRefill] = []byte(string(r))
+	}
+	s.rup = 0
+	s.rupPercent = pct
+}
+
+// refillUpto returns refill amount, percentage based refill is
+// converted according to actual total.
+func (s *barFiller) refillUpto(total int64) int64 {
+	if s.rupPercent > 0 {
+		return int64(s.rupPercent * float64(total) / 100)
+	}
+	return int64(s.rup)
 }
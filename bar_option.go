@@ -1,6 +1,9 @@
 package mpb
 
 import (
+	"time"
+	"unicode/utf8"
+
 	"github.com/vbauerster/mpb/v4/decor"
 )
 
@@ -37,6 +40,37 @@ func PrependDecorators(prependers ...decor.Decorator) BarOption {
 	}
 }
 
+// BarDecoratorSeparator sets separator, which is inserted between
+// adjacent decorators on each side of the bar. Overrides container's
+// WithDecoratorSeparator.
+func BarDecoratorSeparator(sep string) BarOption {
+	return func(s *bState) {
+		s.decorSep = sep
+	}
+}
+
+// BarClock replaces time source of the bar, its time measuring
+// decorators and animated fillers, see decor.Clocked. Meant for deterministic rendering in
+// tests, production code should leave it default, which is time.Now.
+func BarClock(now func() time.Time) BarOption {
+	return func(s *bState) {
+		if now == nil {
+			return
+		}
+		s.clock = now
+	}
+}
+
+// BarStartTime sets time, the bar's operation has started at, so
+// elapsed time, speed and ETA account for work done before the bar was
+// created, e.g. on resume. Time in the future, according to bar's
+// clock, is ignored.
+func BarStartTime(t time.Time) BarOption {
+	return func(s *bState) {
+		s.startTime = t
+	}
+}
+
 // BarID sets bar id.
 func BarID(id int) BarOption {
 	return func(s *bState) {
@@ -51,6 +85,80 @@ func BarWidth(width int) BarOption {
 	}
 }
 
+// BarAutoIncrementTotal makes total of dynamic bar grow by incrBy,
+// every time current reaches triggerPercent of total. Total starts
+// from incrBy as an estimate. Trigger is expected to be within 0..100
+// range, otherwise option is ignored. Bar with fixed total isn't
+// affected. Use SetTotal with final=true, once actual total is known.
+func BarAutoIncrementTotal(triggerPercent, incrBy int64) BarOption {
+	return func(s *bState) {
+		if triggerPercent < 0 || triggerPercent > 100 || incrBy <= 0 {
+			return
+		}
+		s.totalAutoIncrTrigger = triggerPercent
+		s.totalAutoIncrBy = incrBy
+	}
+}
+
+// OverflowMode enum.
+type OverflowMode int
+
+// OverflowMode kinds.
+const (
+	// OverflowClamp clamps current to total, it's the default.
+	OverflowClamp OverflowMode = iota
+	// OverflowGrowTotal makes total follow current. Overshooting
+	// increment doesn't complete the bar, SetTotal with final flag
+	// does.
+	OverflowGrowTotal
+	// OverflowError replaces bar with an error line, the same way as
+	// if a decorator panics.
+	OverflowError
+)
+
+// BarOnOverflow sets what happens, when increment takes current beyond
+// total. The bar completes, unless mode is OverflowGrowTotal.
+func BarOnOverflow(mode OverflowMode) BarOption {
+	return func(s *bState) {
+		s.overflow = mode
+	}
+}
+
+// BarTotalFunc defers computation of total, e.g. a HEAD request, until
+// the bar is rendered first time. fn is called once in its own
+// goroutine, total passed to Add is ignored and the bar behaves as
+// dynamic, until fn has returned. Non positive result leaves the bar
+// dynamic.
+func BarTotalFunc(fn func() int64) BarOption {
+	return func(s *bState) {
+		s.totalFunc = fn
+	}
+}
+
+// BarCompleteThreshold makes bar complete, once current reaches pct
+// percent of total, e.g. 99.9, so off by a few counts streams don't
+// leave the bar hanging. Current snaps to total on completion. It has
+// no effect while total is dynamic. Default is 100, pct out of (0, 100]
+// range is ignored.
+func BarCompleteThreshold(pct float64) BarOption {
+	return func(s *bState) {
+		if pct <= 0 || pct > 100 {
+			return
+		}
+		s.completeThreshold = pct
+	}
+}
+
+// BarNoAutoComplete keeps bar running, when current reaches total.
+// Current keeps growing past total, while fill stays capped at 100%.
+// Completion is left to SetTotal with final flag, so open-ended
+// counters can be monitored.
+func BarNoAutoComplete() BarOption {
+	return func(s *bState) {
+		s.noAutoComplete = true
+	}
+}
+
 // BarRemoveOnComplete is a flag, if set whole bar line will be removed
 // on complete event. If both BarRemoveOnComplete and BarClearOnComplete
 // are set, first bar section gets cleared and then whole bar line
@@ -61,6 +169,25 @@ func BarRemoveOnComplete() BarOption {
 	}
 }
 
+// BarOnRemove sets a callback, which is invoked once bar is removed
+// from the container for good, either by BarRemoveOnComplete or by
+// Abort with remove=true. It is called after the bar's final frame has
+// been rendered, from container's goroutine, so it should be short and
+// must not call methods of the same container.
+func BarOnRemove(fn func()) BarOption {
+	return func(s *bState) {
+		s.onRemove = fn
+	}
+}
+
+// BarGroup tags the bar with group name, so all bars of a job can be
+// aborted at once with Progress.AbortGroup.
+func BarGroup(name string) BarOption {
+	return func(s *bState) {
+		s.group = name
+	}
+}
+
 // BarReplaceOnComplete is indicator for delayed bar start, after the
 // `runningBar` is complete. To achieve bar replacement effect,
 // `runningBar` should has its `BarRemoveOnComplete` option set.
@@ -79,6 +206,60 @@ func BarClearOnComplete() BarOption {
 	}
 }
 
+// BarDimOnComplete is a flag, if set will render the whole bar line
+// dimmed on complete event, so finished bars stay visible, but don't
+// draw attention. Output is expected to support SGR sequences.
+func BarDimOnComplete() BarOption {
+	return func(s *bState) {
+		s.dimOnComplete = true
+	}
+}
+
+// BarAbortMessage sets message, which is displayed in place of the
+// filler, once bar is aborted by Progress.Abort without removal.
+func BarAbortMessage(message string) BarOption {
+	return func(s *bState) {
+		s.abortMsg = message
+	}
+}
+
+// BarForceRefreshOnIncr makes bar request refresh ahead of container's
+// refresh rate, once it has been incremented by at least minDelta since
+// previous request. Requests are coalesced, if refresh is pending
+// already, and WithMaxFPS limit is respected.
+func BarForceRefreshOnIncr(minDelta int64) BarOption {
+	return func(s *bState) {
+		s.forceRefreshDelta = minDelta
+	}
+}
+
+// BarAutoCompleteOnEOF makes bar complete, once reader returned by
+// ProxyReader hits io.EOF. Total is set to amount read, so stream of
+// unknown size ends up at 100%.
+func BarAutoCompleteOnEOF() BarOption {
+	return func(s *bState) {
+		s.autoCompleteOnEOF = true
+	}
+}
+
+// BarIndent prepends n spaces to the bar's line, so sub bars can be
+// visually nested under parent one. Indent is taken out of available
+// width, decorators' width sync is unaffected.
+func BarIndent(n int) BarOption {
+	return func(s *bState) {
+		s.indent = n
+	}
+}
+
+// BarUserData attaches arbitrary value to the bar, which is passed to
+// decorators as decor.Statistics.UserData. It lets a single decorator
+// implementation read per bar data, without closure per bar.
+func BarUserData(v interface{}) BarOption {
+	return func(s *bState) {
+		s.userData = v
+	}
+}
+
 // BarPriority sets bar's priority. Zero is highest priority, i.e. bar
 // will be on top. If `BarReplaceOnComplete` option is supplied, this
 // option is ignored.
@@ -96,10 +277,59 @@ func BarExtender(extender Filler) BarOption {
 	}
 }
 
+// BarPreRender sets a callback, which is invoked on each render cycle
+// just before decorators run. It may enrich or adjust the Statistics,
+// decorators are about to receive. The callback is called from bar's
+// own goroutine, so it doesn't need extra synchronization with
+// decorators of the same bar.
+func BarPreRender(fn func(*decor.Statistics)) BarOption {
+	return func(s *bState) {
+		s.preRender = fn
+	}
+}
+
+// BarETARefreshInterval limits how often displayed ETA value changes.
+// Underlying estimate keeps updating, only displayed value is held
+// for at least d. Effective with decorators implementing
+// decor.RefreshLimiter, which all ETA decorators do.
+func BarETARefreshInterval(d time.Duration) BarOption {
+	return func(s *bState) {
+		s.etaRefresh = d
+	}
+}
+
+// BarETAAlpha overrides smoothing factor of EwmaETA decorators of the
+// bar. Lower alpha gives steadier ETA, higher reacts faster. Alpha out
+// of (0, 1] range is ignored.
+func BarETAAlpha(alpha float64) BarOption {
+	return func(s *bState) {
+		if alpha <= 0 || alpha > 1 {
+			return
+		}
+		s.etaAlpha = alpha
+	}
+}
+
 // TrimSpace trims bar's edge spaces.
 func TrimSpace() BarOption {
+	return BarTrimSpace(true)
+}
+
+// BarTrimSpace sets whether bar's edge spaces are trimmed, overriding
+// container's WithTrimSpace.
+func BarTrimSpace(trim bool) BarOption {
 	return func(s *bState) {
-		s.trimSpace = true
+		s.trimSpace = trim
+	}
+}
+
+// BarMinimal renders filler only, edge to edge: no decorators, no
+// indent and no edge spaces. Decorators, if any, are dropped, including
+// ones added later. Bar is still determinate, so it's handy for compact
+// displays.
+func BarMinimal() BarOption {
+	return func(s *bState) {
+		s.minimal = true
 	}
 }
 
@@ -119,6 +349,97 @@ func BarStyle(style string) BarOption {
 	return MakeFillerTypeSpecificBarOption(chk, cb)
 }
 
+// BarCompleteFill sets rune, which fills the bar on complete event.
+// Effective when Filler type is bar.
+func BarCompleteFill(r rune) BarOption {
+	chk := func(filler Filler) (interface{}, bool) {
+		if !utf8.ValidRune(r) {
+			return nil, false
+		}
+		t, ok := filler.(*barFiller)
+		return t, ok
+	}
+	cb := func(t interface{}) {
+		t.(*barFiller).cfill = []byte(string(r))
+	}
+	return MakeFillerTypeSpecificBarOption(chk, cb)
+}
+
+// BarSmoothTip makes bar's tip reflect fractional progress of the
+// last cell, by means of eighth block runes. It makes slow progress
+// visible at small widths. Effective when Filler type is bar.
+func BarSmoothTip() BarOption {
+	chk := func(filler Filler) (interface{}, bool) {
+		t, ok := filler.(*barFiller)
+		return t, ok
+	}
+	cb := func(t interface{}) {
+		t.(*barFiller).smoothTip = true
+	}
+	return MakeFillerTypeSpecificBarOption(chk, cb)
+}
+
+// BarNoTip makes fill reach empty cells directly, without distinct tip
+// rune, which suits block styles. Effective when Filler type is bar.
+func BarNoTip() BarOption {
+	chk := func(filler Filler) (interface{}, bool) {
+		t, ok := filler.(*barFiller)
+		return t, ok
+	}
+	cb := func(t interface{}) {
+		t.(*barFiller).noTip = true
+	}
+	return MakeFillerTypeSpecificBarOption(chk, cb)
+}
+
+// BarTipAnimation makes bar's tip cycle through provided frames, one
+// frame per interval, so a stalled but alive bar doesn't look frozen.
+// Effective when Filler type is bar.
+func BarTipAnimation(frames []rune, interval time.Duration) BarOption {
+	chk := func(filler Filler) (interface{}, bool) {
+		if len(frames) == 0 || interval <= 0 {
+			return nil, false
+		}
+		t, ok := filler.(*barFiller)
+		return t, ok
+	}
+	cb := func(t interface{}) {
+		anim := &tipAnimation{
+			interval:  interval,
+			clock:     time.Now,
+			startTime: time.Now(),
+		}
+		for _, r := range frames {
+			anim.frames = append(anim.frames, []byte(string(r)))
+		}
+		t.(*barFiller).tipAnim = anim
+	}
+	return MakeFillerTypeSpecificBarOption(chk, cb)
+}
+
+// BarSpinnerPrefix renders spinner frame in front of the filler, so
+// bar with known total shows liveness along with progress. Spinner
+// takes width of the widest frame plus one space, out of bar's width.
+// If frames are empty, default spinner style is used.
+func BarSpinnerPrefix(frames ...string) BarOption {
+	if len(frames) == 0 {
+		frames = defaultSpinnerStyle
+	}
+	var width int
+	for _, frame := range frames {
+		if w := utf8.RuneCountInString(frame); w > width {
+			width = w
+		}
+	}
+	return func(s *bState) {
+		s.spinner = &spinnerFiller{
+			frames:    frames,
+			alignment: SpinnerOnLeft,
+		}
+		s.spinnerWidth = width + 1
+	}
+}
+
 // SpinnerStyle sets custom spinner style.
 // Effective when Filler type is spinner.
 func SpinnerStyle(frames []string) BarOption {
@@ -135,6 +456,35 @@ func SpinnerStyle(frames []string) BarOption {
 	return MakeFillerTypeSpecificBarOption(chk, cb)
 }
 
+// SpinnerFullWidth makes spinner frame repeat across the whole bar
+// width, rather than occupy a single spot. Cells left over, when width
+// isn't a multiple of frame width, are placed according to alignment.
+// Effective when Filler type is spinner.
+func SpinnerFullWidth() BarOption {
+	chk := func(filler Filler) (interface{}, bool) {
+		t, ok := filler.(*spinnerFiller)
+		return t, ok
+	}
+	cb := func(t interface{}) {
+		t.(*spinnerFiller).fullWidth = true
+	}
+	return MakeFillerTypeSpecificBarOption(chk, cb)
+}
+
+// SpinnerCompleteFrame makes spinner stop, once the bar has completed,
+// and show provided glyph, like '✓', in place of animation.
+// Effective when Filler type is spinner.
+func SpinnerCompleteFrame(r rune) BarOption {
+	chk := func(filler Filler) (interface{}, bool) {
+		t, ok := filler.(*spinnerFiller)
+		return t, ok
+	}
+	cb := func(t interface{}) {
+		t.(*spinnerFiller).doneFrame = string(r)
+	}
+	return MakeFillerTypeSpecificBarOption(chk, cb)
+}
+
 // MakeFillerTypeSpecificBarOption makes BarOption specific to Filler's
 // actual type. If you implement your own Filler, so most probably
 // you'll need this. See BarStyle or SpinnerStyle for example.
@@ -2,9 +2,12 @@ package mpb_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -31,6 +34,123 @@ func TestBarCompleted(t *testing.T) {
 	}
 }
 
+func TestBarAccessorsAfterShutdown(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+	total := 100
+	bar := p.AddBar(int64(total))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			bar.ID()
+			bar.Current()
+			bar.Completed()
+			bar.SetRefill(1)
+		}
+	}()
+
+	bar.IncrBy(total)
+	p.Wait()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Accessors blocked after shutdown")
+	}
+
+	if !bar.Completed() {
+		t.Error("Expected completed bar after shutdown")
+	}
+}
+
+func TestBarIsDynamic(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(0)
+	if !bar.IsDynamic() {
+		t.Error("Expected bar with zero total to be dynamic")
+	}
+
+	bar.SetTotal(100, false)
+	if !bar.IsDynamic() {
+		t.Error("Expected bar to stay dynamic till final total")
+	}
+
+	bar.SetTotal(100, true)
+	if bar.IsDynamic() {
+		t.Error("Expected bar not to be dynamic after final total")
+	}
+
+	p.Wait()
+}
+
+func TestBarTotalFunc(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard), WithRefreshRate(10*time.Millisecond))
+
+	resolved := make(chan struct{})
+	bar := p.AddBar(0, BarTotalFunc(func() int64 {
+		defer close(resolved)
+		return 100
+	}))
+	if !bar.IsDynamic() {
+		t.Error("Expected dynamic bar, until total is resolved")
+	}
+	bar.IncrBy(100)
+
+	<-resolved
+	// resolved total completes the bar, otherwise Wait blocks forever
+	p.Wait()
+
+	if bar.IsDynamic() {
+		t.Error("Expected resolved total")
+	}
+	if current := bar.Current(); current != 100 {
+		t.Errorf("Expected current: %d, got: %d\n", 100, current)
+	}
+}
+
+func TestBarRescale(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(200)
+	bar.IncrBy(50)
+	bar.Rescale(100)
+
+	if current := bar.Current(); current != 25 {
+		t.Errorf("Expected current: %d, got: %d\n", 25, current)
+	}
+	if percent := bar.Percent(); percent != 25 {
+		t.Errorf("Expected percent: %v, got: %v\n", 25, percent)
+	}
+
+	bar.IncrBy(75)
+	p.Wait()
+}
+
+func TestBarPercentDynamic(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(0)
+	bar.IncrBy(50)
+
+	if percent := bar.Percent(); percent != 0 {
+		t.Errorf("Expected percent of dynamic bar: %v, got: %v\n", 0, percent)
+	}
+
+	bar.SetTotal(200, false)
+	if percent := bar.Percent(); percent != 0 {
+		t.Errorf("Expected percent of still dynamic bar: %v, got: %v\n", 0, percent)
+	}
+
+	bar.SetTotal(100, true)
+	if percent := bar.Percent(); percent != 100 {
+		t.Errorf("Expected percent: %v, got: %v\n", 100, percent)
+	}
+
+	p.Wait()
+}
+
 func TestBarID(t *testing.T) {
 	p := New(WithOutput(ioutil.Discard))
 	total := 80
@@ -87,6 +207,67 @@ func TestBarSetRefill(t *testing.T) {
 	}
 }
 
+func TestBarResumeFillPercent(t *testing.T) {
+	width := 100
+	total := 100
+	till := 30
+
+	// setup returns count of increments left
+	tests := map[string]func(bar *Bar) int{
+		"resume": func(bar *Bar) int {
+			bar.ResumeFillPercent('#', float64(till))
+			bar.IncrBy(till)
+			return total - till
+		},
+		"before increment": func(bar *Bar) int {
+			bar.ResumeFillPercent('#', float64(till))
+			time.Sleep(50 * time.Millisecond)
+			bar.IncrBy(till)
+			return total - till
+		},
+		"total grown": func(bar *Bar) int {
+			bar.ResumeFillPercent('#', float64(till))
+			bar.IncrBy(till)
+			bar.SetTotal(int64(total*2), false)
+			time.Sleep(50 * time.Millisecond)
+			return total*2 - till
+		},
+	}
+
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := New(
+				WithOutput(&buf),
+				WithWidth(width),
+				WithRefreshRate(10*time.Millisecond),
+			)
+
+			bar := p.AddBar(int64(total), TrimSpace())
+			for i, n := 0, setup(bar); i < n; i++ {
+				bar.Increment()
+				time.Sleep(time.Millisecond)
+			}
+
+			p.Wait()
+
+			wantBar := fmt.Sprintf("[%s%s]",
+				strings.Repeat("#", till-1),
+				strings.Repeat("=", total-till-1),
+			)
+
+			got := string(getLastLine(buf.Bytes()))
+
+			if !strings.Contains(got, wantBar) {
+				t.Errorf("Want bar: %q, got bar: %q\n", wantBar, got)
+			}
+			if strings.Contains(buf.String(), "panic") {
+				t.Error("Filler has panicked")
+			}
+		})
+	}
+}
+
 func TestBarStyle(t *testing.T) {
 	var buf bytes.Buffer
 	customFormat := "╢▌▌░╟"
@@ -114,6 +295,533 @@ func TestBarStyle(t *testing.T) {
 	}
 }
 
+func TestBarCompleteFill(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+	total := 80
+	bar := p.AddBar(int64(total), BarCompleteFill('#'), TrimSpace())
+
+	for i := 0; i < total; i++ {
+		bar.Increment()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p.Wait()
+
+	wantBar := fmt.Sprintf("[%s]", strings.Repeat("#", total-2))
+	got := string(getLastLine(buf.Bytes()))
+
+	if !strings.Contains(got, wantBar) {
+		t.Errorf("Want bar: %q, got bar: %q\n", wantBar, got)
+	}
+}
+
+func TestBarOnRemove(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	var count int
+	total := 50
+	bar := p.AddBar(int64(total), BarRemoveOnComplete(), BarOnRemove(func() { count++ }))
+
+	for i := 0; i < total; i++ {
+		bar.Increment()
+	}
+
+	p.Wait()
+
+	if count != 1 {
+		t.Errorf("Expected OnRemove called once, got %d\n", count)
+	}
+}
+
+func TestBarOnOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+
+	total := 10
+	bar := p.AddBar(int64(total), BarOnOverflow(OverflowError))
+	bar.IncrBy(total + 1)
+
+	p.Wait()
+
+	want := fmt.Sprintf("overflow: current %d exceeds total %d", total+1, total)
+	got := string(getLastLine(buf.Bytes()))
+
+	if !strings.Contains(got, want) {
+		t.Errorf("%q doesn't contain %q\n", got, want)
+	}
+}
+
+func TestBarOnOverflowErrorSyncWidth(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(
+		WithOutput(&buf),
+		WithRefreshRate(10*time.Millisecond),
+	)
+
+	failing := p.AddBar(10,
+		BarOnOverflow(OverflowError),
+		PrependDecorators(decor.Name("failing", decor.WCSyncWidth)),
+	)
+	bar := p.AddBar(10,
+		PrependDecorators(decor.Name("bar", decor.WCSyncWidth)),
+	)
+
+	failing.IncrBy(11)
+	for i := 0; i < 10; i++ {
+		bar.Increment()
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Progress got stuck on overflown bar with synced decorator")
+	}
+
+	if !strings.Contains(buf.String(), "overflow:") {
+		t.Error("Overflow error has not been rendered")
+	}
+}
+
+func TestBarOnOverflowGrowTotal(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(100, BarOnOverflow(OverflowGrowTotal))
+	bar.IncrBy(150)
+
+	if bar.Completed() {
+		t.Error("Bar completed on overshoot")
+	}
+
+	bar.IncrBy(10)
+	if current := bar.Current(); current != 160 {
+		t.Errorf("Expected current: %d, got: %d\n", 160, current)
+	}
+
+	bar.SetTotal(160, true)
+	if !bar.Completed() {
+		t.Error("Bar hasn't completed by SetTotal")
+	}
+
+	p.Wait()
+}
+
+func TestBarIncrAfterCompletion(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	total := 10
+	bar := p.AddBar(int64(total), BarOnOverflow(OverflowGrowTotal))
+	bar.IncrBy(total)
+	bar.IncrBy(5)
+
+	if current := bar.Current(); current != int64(total) {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+
+	p.Wait()
+}
+
+func TestBarNoAutoComplete(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard), WithRefreshRate(10*time.Millisecond))
+
+	bar := p.AddBar(10, BarNoAutoComplete())
+	bar.IncrBy(25)
+	time.Sleep(50 * time.Millisecond)
+
+	if current := bar.Current(); current != 25 {
+		t.Errorf("Expected current: %d, got: %d\n", 25, current)
+	}
+	if bar.Completed() {
+		t.Error("Bar completed on its own")
+	}
+
+	bar.SetTotal(25, true)
+	p.Wait()
+}
+
+func TestBarSetTotalZeroFinal(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+
+	bar := p.AddBar(0, PrependDecorators(decor.CountersNoUnit("%d/%d")))
+	bar.SetTotal(0, true)
+
+	p.Wait()
+
+	if !bar.Completed() {
+		t.Error("Empty task isn't completed")
+	}
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.Contains(got, "0/0 [====") {
+		t.Errorf("Expected full 0/0 bar, got: %q\n", got)
+	}
+}
+
+func TestBarCompleteThreshold(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(1000, BarCompleteThreshold(99.9))
+	bar.IncrBy(998)
+	if bar.Completed() {
+		t.Error("Bar completed below threshold")
+	}
+	bar.IncrBy(1)
+
+	// would block forever, if bar isn't complete
+	p.Wait()
+
+	if current := bar.Current(); current != 1000 {
+		t.Errorf("Expected current: %d, got: %d\n", 1000, current)
+	}
+}
+
+func TestBarIncrInt64(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	// past max int32, so doesn't fit int on 32-bit platforms
+	total := int64(1) << 40
+	bar := p.AddBar(total)
+
+	bar.IncrInt64(total - 1)
+	if current := bar.Current(); current != total-1 {
+		t.Errorf("Expected current: %d, got: %d\n", total-1, current)
+	}
+	if bar.Completed() {
+		t.Error("Bar completed before reaching total")
+	}
+
+	bar.IncrInt64(1)
+	p.Wait()
+
+	if !bar.Completed() {
+		t.Error("Bar isn't completed")
+	}
+	if current := bar.Current(); current != total {
+		t.Errorf("Expected current: %d, got: %d\n", total, current)
+	}
+}
+
+func TestBarFiller(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	filler := &countFiller{}
+	bar := p.Add(10, filler)
+
+	if got := bar.Filler(); got != filler {
+		t.Errorf("Want filler: %p, got: %v\n", filler, got)
+	}
+
+	bar.IncrBy(10)
+	p.Wait()
+
+	// available after bar has quit as well
+	if got := bar.Filler(); got != filler {
+		t.Errorf("Want filler after quit: %p, got: %v\n", filler, got)
+	}
+	if filler.count == 0 {
+		t.Error("Filler hasn't been used")
+	}
+}
+
+type countFiller struct {
+	count int
+}
+
+func (f *countFiller) Fill(w io.Writer, width int, stat *decor.Statistics) {
+	f.count++
+}
+
+func TestBarIncrByDuration(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	d := &amountDecorator{}
+	d.Init()
+	bar := p.AddBar(30, AppendDecorators(d))
+
+	durs := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	for _, dur := range durs {
+		bar.IncrByDuration(10, dur)
+	}
+	p.Wait()
+
+	if got, want := fmt.Sprint(d.durs), fmt.Sprint(durs); got != want {
+		t.Errorf("Want durations: %s, got: %s\n", want, got)
+	}
+	if d.amount != 30 {
+		t.Errorf("Want amount: %d, got: %d\n", 30, d.amount)
+	}
+}
+
+type amountDecorator struct {
+	decor.WC
+	amount int64
+	durs   []time.Duration
+}
+
+func (d *amountDecorator) Decor(st *decor.Statistics) string {
+	return d.FormatMsg("")
+}
+
+func (d *amountDecorator) NextAmount(n int64, wdd ...time.Duration) {
+	d.amount += n
+	d.durs = append(d.durs, wdd...)
+}
+
+func TestBarUserData(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+
+	type file struct{ name string }
+	name := func(st *decor.Statistics) string {
+		return st.UserData.(*file).name
+	}
+
+	bar := p.AddBar(10,
+		BarUserData(&file{"data.bin"}),
+		PrependDecorators(decor.Any(name)),
+	)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.Contains(got, "data.bin [") {
+		t.Errorf("Expected user data in %q\n", got)
+	}
+}
+
+func TestBarPreRender(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	var calls int
+	bar := p.AddBar(10,
+		BarPreRender(func(st *decor.Statistics) {
+			calls++
+			st.UserData = fmt.Sprintf("%d left", st.Total-st.Current)
+		}),
+		PrependDecorators(decor.Any(func(st *decor.Statistics) string {
+			return st.UserData.(string)
+		})),
+	)
+	bar.IncrBy(3)
+
+	frame, err := p.RenderFrame()
+	if err != nil {
+		t.Fatalf("RenderFrame: %v\n", err)
+	}
+	if !bytes.HasPrefix(frame, []byte("7 left [")) {
+		t.Errorf("Expected adjusted statistics in %q\n", frame)
+	}
+
+	bar.IncrBy(7)
+	p.Wait()
+
+	if calls == 0 {
+		t.Error("Pre render hook hasn't been called")
+	}
+}
+
+func TestBarStartTime(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+
+	bar := p.AddBar(10,
+		BarStartTime(time.Now().Add(-time.Hour)),
+		PrependDecorators(decor.Elapsed(decor.ET_STYLE_HHMMSS)),
+	)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.Contains(got, "01:00:0") {
+		t.Errorf("Expected elapsed since start time, got: %q\n", got)
+	}
+}
+
+func TestBarSetError(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+
+	bar := p.AddBar(10, AppendDecorators(decor.OnError(decor.Name("ok"))))
+	if err := bar.Err(); err != nil {
+		t.Errorf("Unexpected error: %v\n", err)
+	}
+	boom := errors.New("boom")
+	bar.SetError(boom)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	if err := bar.Err(); err != boom {
+		t.Errorf("Expected error: %v, got: %v\n", boom, err)
+	}
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.HasSuffix(got, "] boom") {
+		t.Errorf("Expected error message in %q\n", got)
+	}
+}
+
+func TestBarAbortMessage(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf))
+
+	bar := p.AddBar(100, BarAbortMessage("cancelled"))
+	bar.IncrBy(30)
+	p.Abort(bar, false)
+
+	p.Wait()
+
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.Contains(got, "cancelled") {
+		t.Errorf("Expected abort message in %q\n", got)
+	}
+}
+
+func TestBarForceRefreshOnIncr(t *testing.T) {
+	var buf safeBuffer
+	p := New(
+		WithOutput(&buf),
+		WithManualRefresh(make(chan time.Time)),
+	)
+
+	bar := p.AddBar(100, BarForceRefreshOnIncr(10))
+	bar.IncrBy(5)
+	time.Sleep(50 * time.Millisecond)
+	if buf.Len() != 0 {
+		t.Error("Unexpected refresh below threshold")
+	}
+
+	bar.IncrBy(5)
+	deadline := time.Now().Add(time.Second)
+	for buf.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if buf.Len() == 0 {
+		t.Error("Expected forced refresh")
+	}
+
+	bar.IncrBy(90)
+	p.Wait()
+}
+
+func TestBarDecoratorsChangeAtRuntime(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf), WithRefreshRate(10*time.Millisecond))
+
+	total := 50
+	bars := make([]*Bar, 2)
+	for i := range bars {
+		bars[i] = p.AddBar(int64(total),
+			PrependDecorators(decor.Name(fmt.Sprintf("bar%d", i), decor.WCSyncWidth)),
+		)
+	}
+
+	for i := 0; i < total; i++ {
+		switch i {
+		case 10:
+			bars[0].AppendDecorators(decor.Name("added", decor.WCSyncWidth))
+		case 20:
+			bars[1].RemoveAllPrependers()
+		}
+		for _, b := range bars {
+			b.Increment()
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Progress got stuck after decorators change")
+	}
+
+	if !strings.Contains(buf.String(), "added") {
+		t.Error("Added decorator has not been rendered")
+	}
+}
+
+func TestBarAppendDecoratorsClock(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(
+		WithOutput(&buf),
+		WithRefreshRate(10*time.Millisecond),
+	)
+
+	var offset int64
+	clock := func() time.Time {
+		return time.Unix(atomic.LoadInt64(&offset), 0)
+	}
+
+	bar := p.AddBar(100, BarClock(clock))
+	bar.AppendDecorators(decor.Elapsed(decor.ET_STYLE_GO))
+	time.Sleep(50 * time.Millisecond)
+
+	// runtime added decorator follows bar's clock and start time
+	atomic.StoreInt64(&offset, int64(time.Hour/time.Second))
+	bar.IncrBy(100)
+
+	p.Wait()
+
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.Contains(got, "1h0m0s") {
+		t.Errorf("Expected elapsed by bar's clock, got: %q\n", got)
+	}
+}
+
+func TestBarFinalLine(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard), WithWidth(20))
+
+	bar := p.AddBar(10, PrependDecorators(decor.Name("archived")))
+	if line := bar.FinalLine(); line != "" {
+		t.Errorf("Expected empty line before completion, got: %q\n", line)
+	}
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	want := "archived [========] "
+	if line := bar.FinalLine(); line != want {
+		t.Errorf("Want: %q, Got: %q\n", want, line)
+	}
+}
+
+func TestBarRemoveDecorator(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(WithOutput(&buf), WithRefreshRate(10*time.Millisecond))
+
+	bar := p.AddBar(10,
+		PrependDecorators(
+			decor.Name("keep", decor.WCSyncSpaceR),
+			decor.Name("transient", decor.WCSyncSpaceR),
+		),
+	)
+	bar.RemovePrepender(1)
+	bar.RemovePrepender(5)
+	time.Sleep(50 * time.Millisecond)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.Contains(got, "keep") || strings.Contains(got, "transient") {
+		t.Errorf("Expected transient decorator removed, got: %q\n", got)
+	}
+}
+
 func TestBarPanics(t *testing.T) {
 	var buf bytes.Buffer
 	p := New(WithDebugOutput(&buf), WithOutput(ioutil.Discard))
@@ -158,3 +866,294 @@ func (d *decorator) Decor(st *decor.Statistics) string {
 	}
 	return d.FormatMsg("")
 }
+
+func TestBarMinimalSyncWidth(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(
+		WithOutput(&buf),
+		WithRefreshRate(10*time.Millisecond),
+	)
+
+	minimal := p.AddBar(100,
+		BarMinimal(),
+		PrependDecorators(decor.Name("x", decor.WCSyncWidth)),
+	)
+	bar := p.AddBar(100,
+		PrependDecorators(decor.Name("name", decor.WCSyncWidth)),
+	)
+	minimal.AppendDecorators(decor.Name("y", decor.WCSyncWidth))
+
+	for i := 0; i < 10; i++ {
+		minimal.IncrBy(10)
+		bar.IncrBy(10)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Progress got stuck on minimal bar with synced decorator")
+	}
+
+	if !strings.Contains(buf.String(), "name") {
+		t.Error("Synced decorator of regular bar has not been rendered")
+	}
+}
+
+func TestBarNestedWrappersSyncWidth(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(
+		WithOutput(&buf),
+		WithRefreshRate(10*time.Millisecond),
+	)
+
+	bars := make([]*Bar, 2)
+	for i := range bars {
+		bars[i] = p.AddBar(10,
+			PrependDecorators(
+				decor.OnComplete(decor.OnError(decor.Name("x", decor.WCSyncWidth)), "done"),
+				decor.OnError(decor.OnComplete(decor.Spinner(nil, decor.WCSyncWidth), "ok")),
+			),
+		)
+	}
+
+	// first bar completes, while the other one keeps rendering
+	bars[0].IncrBy(10)
+	for i := 0; i < 10; i++ {
+		bars[1].Increment()
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Progress got stuck on completed bar with wrapped synced decorator")
+	}
+
+	if got := buf.String(); !strings.Contains(got, "done") {
+		t.Errorf("Complete message has not been rendered: %q\n", got)
+	}
+}
+
+func TestBarDecoratorSeparatorSyncWidth(t *testing.T) {
+	p := New(
+		WithOutput(ioutil.Discard),
+		WithWidth(40),
+		WithDecoratorSeparator(" | "),
+	)
+
+	p.AddBar(100,
+		PrependDecorators(
+			decor.Name("a", decor.WCSyncWidth),
+			decor.Name("first", decor.WCSyncWidth),
+		),
+		AppendDecorators(decor.Name("x", decor.WCSyncWidth), decor.Name("end")),
+	)
+	p.AddBar(100,
+		BarDecoratorSeparator(" : "),
+		PrependDecorators(
+			decor.Name("longer", decor.WCSyncWidth),
+			decor.Name("b", decor.WCSyncWidth),
+		),
+		AppendDecorators(decor.Name("xyz", decor.WCSyncWidth), decor.Name("end")),
+	)
+
+	frame, err := p.RenderFrame()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(frame), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Want 2 lines, got: %q\n", lines)
+	}
+
+	want := []string{
+		"     a | first [",
+		"longer :     b [",
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, want[i]) {
+			t.Errorf("Line %d: want prefix %q, got: %q\n", i, want[i], line)
+		}
+	}
+	// append columns line up too
+	if i, j := strings.Index(lines[0], "] "), strings.Index(lines[1], "] "); i != j {
+		t.Errorf("Bars end at different columns: %d vs %d\n", i, j)
+	}
+	if i, j := strings.Index(lines[0], "end"), strings.Index(lines[1], "end"); i != j {
+		t.Errorf("Append columns aren't aligned: %q\n", lines)
+	}
+}
+
+func TestBarDimOnComplete(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(
+		WithOutput(&buf),
+		WithRefreshRate(10*time.Millisecond),
+	)
+
+	dim := p.AddBar(10,
+		BarDimOnComplete(),
+		PrependDecorators(decor.Name("dim")),
+	)
+	bar := p.AddBar(10,
+		BarDimOnComplete(),
+		PrependDecorators(decor.Name("run")),
+	)
+
+	// completed bar keeps being rendered, while the other one runs
+	dim.IncrBy(10)
+	for i := 0; i < 10; i++ {
+		bar.Increment()
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.Wait()
+
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[2mdim ") {
+		t.Errorf("Completed bar isn't dimmed: %q\n", out)
+	}
+	if !strings.HasSuffix(out, "\x1b[0m\n") {
+		t.Errorf("Dimmed line isn't reset: %q\n", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "\x1b[2mrun ") && strings.Contains(line, ">") {
+			t.Errorf("Running bar has been dimmed: %q\n", line)
+		}
+	}
+}
+
+func TestSpinnerCompleteFrame(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(
+		WithOutput(&buf),
+		WithWidth(10),
+		WithRefreshRate(10*time.Millisecond),
+	)
+
+	bar := p.AddSpinner(10, SpinnerOnLeft, SpinnerCompleteFrame('✓'), TrimSpace())
+	for i := 0; i < 5; i++ {
+		bar.Increment()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if frame, _ := p.RenderFrame(); bytes.ContainsRune(frame, '✓') {
+		t.Errorf("Complete frame rendered before completion: %q\n", frame)
+	}
+	bar.IncrBy(5)
+	p.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	// spinner is left aligned within width
+	want := "✓" + strings.Repeat(" ", 9)
+	if got := lines[len(lines)-1]; !strings.HasSuffix(got, "\x1b[J"+want) {
+		t.Errorf("Want last frame: %q, got: %q\n", want, got)
+	}
+}
+
+func TestBarNoTip(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []BarOption
+		want    string
+	}{
+		{"default", nil, "[=====>----]"},
+		{"no tip", []BarOption{BarNoTip()}, "[======----]"},
+	}
+
+	for _, test := range tests {
+		p := New(WithOutput(ioutil.Discard), WithWidth(12))
+		bar := p.AddBar(80, append(test.options, TrimSpace())...)
+		bar.IncrBy(45)
+		frame, err := p.RenderFrame()
+		if err != nil {
+			t.Fatalf("%s: RenderFrame: %v\n", test.name, err)
+		}
+		if got := strings.TrimSuffix(string(frame), "\n"); got != test.want {
+			t.Errorf("%s: want: %q, got: %q\n", test.name, test.want, got)
+		}
+		bar.IncrBy(35)
+		p.Wait()
+	}
+}
+
+func TestBarSmoothTip(t *testing.T) {
+	// 10 cells of 80 total, so each 1 of current is an eighth of a cell
+	tests := []struct {
+		current int
+		want    string
+	}{
+		{0, "[----------]"},
+		{1, "[▏---------]"},
+		{40, "[=====-----]"},
+		{45, "[=====▋----]"},
+		{79, "[=========▉]"},
+	}
+
+	for _, test := range tests {
+		p := New(WithOutput(ioutil.Discard), WithWidth(12))
+		bar := p.AddBar(80, BarSmoothTip(), TrimSpace())
+		bar.IncrBy(test.current)
+		frame, err := p.RenderFrame()
+		if err != nil {
+			t.Fatalf("current %d: RenderFrame: %v\n", test.current, err)
+		}
+		if got := strings.TrimSuffix(string(frame), "\n"); got != test.want {
+			t.Errorf("current %d: want: %q, got: %q\n", test.current, test.want, got)
+		}
+		bar.IncrBy(80 - test.current)
+		p.Wait()
+	}
+}
+
+func TestBarAutoIncrementTotal(t *testing.T) {
+	p := New(WithOutput(ioutil.Discard))
+
+	frame := func() string {
+		b, err := p.RenderFrame()
+		if err != nil {
+			t.Fatalf("RenderFrame: %v\n", err)
+		}
+		return string(b)
+	}
+
+	dynamic := p.AddBar(0,
+		BarAutoIncrementTotal(80, 100),
+		AppendDecorators(decor.CountersNoUnit("%d / %d")),
+	)
+	dynamic.IncrBy(50)
+	if got := frame(); !strings.Contains(got, "50 / 100") {
+		t.Errorf("Expected estimated total 100, got: %q\n", got)
+	}
+	dynamic.IncrBy(30)
+	if got := frame(); !strings.Contains(got, "80 / 200") {
+		t.Errorf("Expected total grown to 200, got: %q\n", got)
+	}
+	dynamic.IncrBy(300)
+	if got := frame(); !strings.Contains(got, "380 / 480") {
+		t.Errorf("Expected total grown past current, got: %q\n", got)
+	}
+	if dynamic.Completed() {
+		t.Error("Dynamic bar completed on its own")
+	}
+
+	fixed := p.AddBar(100, BarAutoIncrementTotal(50, 100))
+	fixed.IncrBy(100)
+	if !fixed.Completed() {
+		t.Error("Bar with fixed total hasn't completed")
+	}
+
+	dynamic.SetTotal(380, true)
+	p.Wait()
+}
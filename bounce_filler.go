@@ -0,0 +1,78 @@
+package mpb
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/vbauerster/mpb/v4/decor"
+)
+
+const (
+	// default bouncing block width
+	bounceBlockWidth = 3
+	// time it takes block to move by one cell
+	bounceStep = 80 * time.Millisecond
+)
+
+// bounceFiller renders a fixed size block, which sweeps left and right
+// inside the bar. Position depends on time elapsed since start, so
+// animation speed doesn't depend on refresh rate.
+type bounceFiller struct {
+	format     [][]byte
+	blockWidth int
+	clock      func() time.Time
+	startTime  time.Time
+}
+
+func newBounceFiller() Filler {
+	return &bounceFiller{
+		format:     newDefaultBarFiller().(*barFiller).format,
+		blockWidth: bounceBlockWidth,
+		clock:      time.Now,
+		startTime:  time.Now(),
+	}
+}
+
+// SetClock is implementation of decor.Clocked interface, so animation
+// follows bar's clock, see BarClock.
+func (s *bounceFiller) SetClock(now func() time.Time) {
+	s.clock = now
+	s.startTime = now()
+}
+
+func (s *bounceFiller) Fill(w io.Writer, width int, stat *decor.Statistics) {
+
+	b := s.format[rLeft]
+
+	// don't count rLeft and rRight [brackets]
+	width -= 2
+
+	if width < 2 {
+		return
+	}
+
+	if stat.Completed {
+		b = append(b, bytes.Repeat(s.format[rFill], width)...)
+		w.Write(append(b, s.format[rRight]...))
+		return
+	}
+
+	block := s.blockWidth
+	if block > width {
+		block = width
+	}
+
+	var pos int
+	if span := width - block; span > 0 {
+		pos = int(s.clock().Sub(s.startTime)/bounceStep) % (2 * span)
+		if pos > span {
+			pos = 2*span - pos
+		}
+	}
+
+	b = append(b, bytes.Repeat(s.format[rEmpty], pos)...)
+	b = append(b, bytes.Repeat(s.format[rFill], block)...)
+	b = append(b, bytes.Repeat(s.format[rEmpty], width-pos-block)...)
+	w.Write(append(b, s.format[rRight]...))
+}
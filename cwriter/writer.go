@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"unicode/utf8"
 
+	"github.com/vbauerster/mpb/v4/internal"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -18,32 +21,147 @@ var cuuAndEd = fmt.Sprintf("%c[%%dA%[1]c[J", 27)
 // contents of writer will be flushed when Flush is called.
 type Writer struct {
 	out        io.Writer
+	extra      []io.Writer
 	buf        bytes.Buffer
 	lineCount  int
+	lineWidths []int
+	// above is size of buffered content, which isn't counted, see
+	// WriteAbove
+	above      int
 	fd         uintptr
 	isTerminal bool
+	noCursor   bool
+	// last flushed buffer, kept if skipSame is set
+	last     []byte
+	skipSame bool
 }
 
-// New returns a new Writer with defaults
-func New(out io.Writer) *Writer {
-	w := &Writer{out: out}
+// New returns a new Writer with defaults. If extra writers are
+// provided, output is duplicated to each of them, while terminal
+// related features, like width detection, are bound to out only.
+func New(out io.Writer, extra ...io.Writer) *Writer {
+	w := &Writer{out: out, extra: extra}
 	if f, ok := out.(*os.File); ok {
 		w.fd = f.Fd()
 		w.isTerminal = terminal.IsTerminal(int(w.fd))
 	}
+	if len(extra) != 0 {
+		w.out = io.MultiWriter(append([]io.Writer{out}, extra...)...)
+	}
 	return w
 }
 
 // Flush flushes the underlying buffer
 func (w *Writer) Flush(lineCount int) (err error) {
-	if w.lineCount > 0 {
+	if w.skipSame {
+		if lineCount == w.lineCount && bytes.Equal(w.buf.Bytes(), w.last) {
+			// terminal shows exactly the same already
+			w.buf.Reset()
+			w.above = 0
+			return nil
+		}
+		w.last = append(w.last[:0], w.buf.Bytes()...)
+	}
+	if w.lineCount > 0 && !w.noCursor {
 		w.clearLines()
 	}
 	w.lineCount = lineCount
+	w.lineWidths = w.lineWidths[:0]
+	for _, line := range bytes.Split(w.buf.Bytes()[w.above:], []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		w.lineWidths = append(w.lineWidths, visibleWidth(line))
+	}
+	w.above = 0
 	_, err = w.buf.WriteTo(w.out)
 	return
 }
 
+// DisableCursorControl makes Flush append buffer to previous output,
+// instead of moving cursor up and overwriting it. It's a fallback for
+// terminals, which support neither escape sequences nor console API.
+func (w *Writer) DisableCursorControl() {
+	w.noCursor = true
+}
+
+// DiscardOut stops writing to out, e.g. once its reader has gone.
+// Extra writers, if any, keep receiving output.
+func (w *Writer) DiscardOut() {
+	switch len(w.extra) {
+	case 0:
+		w.out = ioutil.Discard
+	case 1:
+		w.out = w.extra[0]
+	default:
+		w.out = io.MultiWriter(w.extra...)
+	}
+}
+
+// SkipUnchanged makes Flush write nothing, if buffer is the same as
+// the one flushed last time, so static content causes no traffic.
+// Content printed to the terminal by other means isn't tracked, so
+// it's the caller's responsibility to not interfere.
+func (w *Writer) SkipUnchanged() {
+	w.skipSame = true
+}
+
+// Reflow adjusts count of lines to clear on next Flush, so lines
+// wrapped by terminal after its width has shrunk to width are cleared
+// as well.
+func (w *Writer) Reflow(width int) {
+	if width <= 0 || w.lineCount == 0 {
+		return
+	}
+	var lineCount int
+	for i, lw := range w.lineWidths {
+		if i == w.lineCount {
+			break
+		}
+		lineCount++
+		if lw > width {
+			lineCount += (lw - 1) / width
+		}
+	}
+	if lineCount > w.lineCount {
+		w.lineCount = lineCount
+	}
+}
+
+// Buffered returns contents written since last Flush. The slice is
+// valid only until next write or Flush.
+func (w *Writer) Buffered() []byte {
+	return w.buf.Bytes()
+}
+
+// WriteAbove appends p, which isn't part of lineCount passed to Flush,
+// e.g. complete log lines printed above redrawn region. It must go
+// before any other write since last Flush.
+func (w *Writer) WriteAbove(p []byte) (n int, err error) {
+	n, err = w.buf.Write(p)
+	w.above = w.buf.Len()
+	return
+}
+
+// visibleWidth returns width of line on screen, escape sequences,
+// like SGR ones, take no space.
+func visibleWidth(line []byte) int {
+	var n int
+	for i := 0; i < len(line); {
+		if line[i] == 27 && i+1 < len(line) && line[i+1] == '[' {
+			// skip CSI sequence up to its final byte
+			i += 2
+			for i < len(line) && (line[i] < 0x40 || line[i] > 0x7e) {
+				i++
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(line[i:])
+		i += size
+		n += internal.RuneWidth(r)
+	}
+	return n
+}
+
 // Write appends the contents of p to the underlying buffer
 func (w *Writer) Write(p []byte) (n int, err error) {
 	return w.buf.Write(p)
@@ -0,0 +1,42 @@
+// +build !windows
+
+package cwriter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReflowWithLinesAbove(t *testing.T) {
+	tests := []struct {
+		width int
+		want  int
+	}{
+		{20, 2},
+		{10, 2},
+		{6, 3},
+		{4, 4},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		w := New(&out)
+		w.WriteAbove([]byte(strings.Repeat("log ", 20) + "\n"))
+		// 8 columns on screen, SGR sequences take no space
+		w.WriteString("\x1b[2mabcdefgh\x1b[0m\n")
+		w.WriteString("12345\n")
+		w.Flush(2)
+
+		w.Reflow(test.width)
+		out.Reset()
+		w.WriteString("x\n")
+		w.Flush(1)
+
+		want := fmt.Sprintf(cuuAndEd, test.want)
+		if got := out.String(); !strings.HasPrefix(got, want) {
+			t.Errorf("width %d want clear: %q, got: %q\n", test.width, want, got)
+		}
+	}
+}
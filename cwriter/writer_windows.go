@@ -39,7 +39,10 @@ type consoleScreenBufferInfo struct {
 
 func (w *Writer) clearLines() {
 	if !w.isTerminal {
+		// not a console, e.g. mintty or a pipe, escape sequences are
+		// the only option
 		fmt.Fprintf(w.out, cuuAndEd, w.lineCount)
+		return
 	}
 	var info consoleScreenBufferInfo
 	procGetConsoleScreenBufferInfo.Call(w.fd, uintptr(unsafe.Pointer(&info)))
@@ -0,0 +1,37 @@
+package decor
+
+// Any decorator displays text, that can be changed during decorator's
+// lifetime via provided func call back.
+//
+//	`fn` callback function, which receives Statistics on each render
+//
+//	`wcc` optional WC config
+func Any(fn func(*Statistics) string, wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	d := &anyDecorator{
+		WC: wc,
+		fn: fn,
+	}
+	return d
+}
+
+type anyDecorator struct {
+	WC
+	fn          func(*Statistics) string
+	completeMsg *string
+}
+
+func (d *anyDecorator) Decor(st *Statistics) string {
+	if st.Completed && d.completeMsg != nil {
+		return d.FormatMsg(*d.completeMsg)
+	}
+	return d.FormatMsg(d.fn(st))
+}
+
+func (d *anyDecorator) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
@@ -3,6 +3,7 @@ package decor
 import (
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -206,3 +207,130 @@ func (d *countersDecorator) Decor(st *Statistics) string {
 func (d *countersDecorator) OnCompleteMessage(msg string) {
 	d.completeMsg = &msg
 }
+
+// Remaining decorator with dynamic unit measure adjustment. Displays
+// amount left, i.e. total minus current. Nothing is displayed while
+// total is unknown, see Statistics.Dynamic.
+//
+//	`unit` one of [0|UnitKiB|UnitKB] zero for no unit
+//
+//	`format` printf compatible verb for value, like "%f" or "%d"
+//
+//	`wcc` optional WC config
+//
+// format example if UnitKiB is chosen:
+//
+//	"% .1f left" = "4.2 MiB left"
+func Remaining(unit int, format string, wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	d := &remainingDecorator{
+		WC:     wc,
+		unit:   unit,
+		format: format,
+	}
+	return d
+}
+
+type remainingDecorator struct {
+	WC
+	unit        int
+	format      string
+	completeMsg *string
+}
+
+func (d *remainingDecorator) Decor(st *Statistics) string {
+	if st.Completed && d.completeMsg != nil {
+		return d.FormatMsg(*d.completeMsg)
+	}
+	if st.Dynamic {
+		return d.FormatMsg("")
+	}
+
+	remaining := st.Total - st.Current
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	var str string
+	switch d.unit {
+	case UnitKiB:
+		str = fmt.Sprintf(d.format, CounterKiB(remaining))
+	case UnitKB:
+		str = fmt.Sprintf(d.format, CounterKB(remaining))
+	default:
+		str = fmt.Sprintf(d.format, remaining)
+	}
+
+	return d.FormatMsg(str)
+}
+
+func (d *remainingDecorator) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
+
+// AdaptiveBytes decorator displays current amount of bytes, in the
+// largest unit, which keeps value at least 1, i.e. one of
+// [b|KiB|MiB|GiB|TiB]. Value below 10 is displayed with two decimals,
+// otherwise with one, like "4.25MiB" or "512.0KiB".
+//
+//	`wcc` optional WC config
+func AdaptiveBytes(wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	d := &adaptiveBytesDecorator{
+		WC: wc,
+	}
+	return d
+}
+
+type adaptiveBytesDecorator struct {
+	WC
+	completeMsg *string
+}
+
+func (d *adaptiveBytesDecorator) Decor(st *Statistics) string {
+	if st.Completed && d.completeMsg != nil {
+		return d.FormatMsg(*d.completeMsg)
+	}
+	return d.FormatMsg(formatAdaptiveBytes(st.Current))
+}
+
+func (d *adaptiveBytesDecorator) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
+
+func formatAdaptiveBytes(n int64) string {
+	if n < KiB {
+		return strconv.FormatInt(n, 10) + "b"
+	}
+	units := [...]string{"KiB", "MiB", "GiB", "TiB"}
+	size := float64(n) / KiB
+	var i int
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	prec := 2
+	if roundTo(size, prec) >= 10 {
+		prec = 1
+	}
+	// rounding may reach next unit, e.g. 1023.96KiB is 1.00MiB
+	if roundTo(size, prec) >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+		prec = 2
+	}
+	return strconv.FormatFloat(size, 'f', prec, 64) + units[i]
+}
+
+func roundTo(x float64, prec int) float64 {
+	p := math.Pow10(prec)
+	return math.Round(x*p) / p
+}
@@ -140,3 +140,54 @@ func TestCounterKB(t *testing.T) {
 		})
 	}
 }
+
+func TestRemaining(t *testing.T) {
+	tests := []struct {
+		unit   int
+		format string
+		st     Statistics
+		want   string
+	}{
+		{0, "%d left", Statistics{Total: 100, Current: 40}, "60 left"},
+		{0, "%d left", Statistics{Total: 100, Current: 120}, "0 left"},
+		{0, "%d left", Statistics{Total: 100, Current: 40, Dynamic: true}, ""},
+		{UnitKiB, "% .1f left", Statistics{Total: 6 * MiB, Current: 2 * MiB}, "4.0 MiB left"},
+		{UnitKB, "% d left", Statistics{Total: 6 * MB, Current: 2 * MB}, "4 MB left"},
+	}
+
+	for _, test := range tests {
+		d := Remaining(test.unit, test.format)
+		got := d.Decor(&test.st)
+		if got != test.want {
+			t.Errorf("Want: %q, Got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestFormatAdaptiveBytes(t *testing.T) {
+	cases := map[string]struct {
+		value    int64
+		expected string
+	}{
+		"zero":                 {0, "0b"},
+		"1023":                 {1023, "1023b"},
+		"1024":                 {1024, "1.00KiB"},
+		"9.99KiB":              {10229, "9.99KiB"},
+		"9.995KiB up to 10.0":  {10235, "10.0KiB"},
+		"10KiB":                {10 * KiB, "10.0KiB"},
+		"999.95KiB":            {1023949, "1000.0KiB"},
+		"1023.9KiB":            {1048473, "1023.9KiB"},
+		"1023.96KiB up to MiB": {1048535, "1.00MiB"},
+		"1MiB":                 {MiB, "1.00MiB"},
+		"1.5GiB":               {GiB + GiB/2, "1.50GiB"},
+		"1023.97GiB up to TiB": {TiB - GiB/32, "1.00TiB"},
+		"2048TiB":              {2048 * TiB, "2048.0TiB"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := formatAdaptiveBytes(tc.value); got != tc.expected {
+				t.Errorf("Expected: %q, got: %q\n", tc.expected, got)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package decor
 
 import (
-	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
-	"unicode/utf8"
+
+	"github.com/vbauerster/mpb/v4/internal"
 )
 
 const (
@@ -43,12 +45,21 @@ const (
 )
 
 // Statistics consists of progress related statistics, that Decorator
-// may need.
+// may need. Dynamic is true while total isn't known yet, i.e. bar has
+// been created with non positive total and SetTotal hasn't been called
+// with final flag. Items is count of items reported by
+// Bar.IncrWeighted, which is independent of weighted Current.
+// UserData is a value set by BarUserData option, decorators may type
+// assert it to read per bar data. Err is an error set by Bar.SetError.
 type Statistics struct {
 	ID        int
 	Completed bool
+	Dynamic   bool
 	Total     int64
 	Current   int64
+	Items     int64
+	UserData  interface{}
+	Err       error
 }
 
 // Decorator interface.
@@ -77,7 +88,7 @@ type OnCompleteMessenger interface {
 // If decorator needs to receive increment amount, so this is the right
 // interface to implement.
 type AmountReceiver interface {
-	NextAmount(int, ...time.Duration)
+	NextAmount(int64, ...time.Duration)
 }
 
 // ShutdownListener interface.
@@ -87,6 +98,54 @@ type ShutdownListener interface {
 	Shutdown()
 }
 
+// RefreshLimiter interface.
+// Decorators implementing this interface suppose to update displayed
+// value at most once per provided interval.
+type RefreshLimiter interface {
+	SetRefreshInterval(time.Duration)
+}
+
+// EwmaAlphaSetter interface.
+// EWMA based decorators implement this interface, so smoothing factor
+// alpha, in (0, 1] range, can be overridden. Lower alpha gives steadier
+// value, higher reacts faster.
+type EwmaAlphaSetter interface {
+	SetEwmaAlpha(float64)
+}
+
+// ETAResetter interface.
+// ETA decorators implement this interface, so estimation can restart
+// from scratch after a major state change, like rollback of progress.
+type ETAResetter interface {
+	ResetETA()
+}
+
+// Clocked interface.
+// Decorators measuring time implement this interface, so time source
+// can be replaced, e.g. to make rendering deterministic in tests.
+// Setting a clock restarts time measurement.
+type Clocked interface {
+	SetClock(func() time.Time)
+}
+
+// StartTimeSetter interface.
+// Decorators measuring time since start implement this interface, so
+// start can be moved back, e.g. when resumed operation has begun before
+// the bar was created.
+type StartTimeSetter interface {
+	SetStartTime(time.Time)
+}
+
+// clock is a time source, nil clock means time.Now
+type clock func() time.Time
+
+func (c clock) now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c()
+}
+
 // Global convenience shortcuts
 var (
 	WCSyncWidth  = WC{C: DSyncWidth}
@@ -96,39 +155,83 @@ var (
 )
 
 // WC is a struct with two public fields W and C, both of int type.
-// W represents width and C represents bit set of width related config.
+// W represents minimum width and C represents bit set of width related
+// config. With DSyncWidth, column is never narrower than W, so layout
+// stays stable while values grow or shrink.
 // A decorator should embed WC, to enable width synchronization.
 type WC struct {
-	W      int
-	C      int
-	format string
-	wsync  chan int
+	W     int
+	C     int
+	wsync chan int
+	// width is shared by copies, see ColumnWidth
+	width *int64
 }
 
 // FormatMsg formats final message according to WC.W and WC.C.
-// Should be called by any Decorator implementation.
+// Should be called by any Decorator implementation. Width is measured
+// in columns on screen, so wide runes, like CJK ones, count as two.
 func (wc WC) FormatMsg(msg string) string {
+	msgWidth := internal.StringWidth(msg)
 	if (wc.C & DSyncWidth) != 0 {
-		wc.wsync <- utf8.RuneCountInString(msg)
+		wc.wsync <- msgWidth
 		max := <-wc.wsync
-		if max == 0 {
+		if max < wc.W {
 			max = wc.W
 		}
 		if (wc.C & DextraSpace) != 0 {
 			max++
 		}
-		return fmt.Sprintf(fmt.Sprintf(wc.format, max), msg)
+		wc.storeWidth(max)
+		return wc.pad(msg, max-msgWidth)
 	}
-	return fmt.Sprintf(fmt.Sprintf(wc.format, wc.W), msg)
+	width := msgWidth
+	if width < wc.W {
+		width = wc.W
+	}
+	wc.storeWidth(width)
+	return wc.pad(msg, width-msgWidth)
 }
 
-// Init initializes width related config.
-func (wc *WC) Init() {
-	wc.format = "%%"
+// pad adds n spaces to msg, according to DidentRight.
+func (wc WC) pad(msg string, n int) string {
+	if n <= 0 {
+		return msg
+	}
 	if (wc.C & DidentRight) != 0 {
-		wc.format += "-"
+		return msg + strings.Repeat(" ", n)
+	}
+	return strings.Repeat(" ", n) + msg
+}
+
+func (wc WC) storeWidth(width int) {
+	if wc.width != nil {
+		atomic.StoreInt64(wc.width, int64(width))
 	}
-	wc.format += "%ds"
+}
+
+// ColumnWidth returns width of the last formatted message, including
+// padding. With DSyncWidth it's width of the whole column, resolved by
+// width sync, so separators or boxes can be aligned to it. Zero is
+// returned until decorator has been rendered once.
+func (wc *WC) ColumnWidth() int {
+	if wc.width == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(wc.width))
+}
+
+// ColumnWidth returns width of decorator's column, see WC.ColumnWidth.
+// Zero is returned, if decorator doesn't embed WC.
+func ColumnWidth(decorator Decorator) int {
+	if cw, ok := decorator.(interface{ ColumnWidth() int }); ok {
+		return cw.ColumnWidth()
+	}
+	return 0
+}
+
+// Init initializes width related config.
+func (wc *WC) Init() {
+	wc.width = new(int64)
 	if (wc.C & DSyncWidth) != 0 {
 		wc.wsync = make(chan int)
 	}
@@ -141,6 +244,8 @@ func (wc *WC) Sync() (chan int, bool) {
 
 // OnComplete returns decorator, which wraps provided decorator, with
 // sole purpose to display provided message on complete event.
+// Decorators, which don't implement OnCompleteMessenger, are wrapped
+// so any decorator can be used.
 //
 //	`decorator` Decorator to wrap
 //
@@ -148,6 +253,93 @@ func (wc *WC) Sync() (chan int, bool) {
 func OnComplete(decorator Decorator, message string) Decorator {
 	if d, ok := decorator.(OnCompleteMessenger); ok {
 		d.OnCompleteMessage(message)
+		return decorator
+	}
+	return &onCompleteWrapper{
+		wrapper: wrapper{Decorator: decorator, completeMsg: &message},
+	}
+}
+
+type onCompleteWrapper struct {
+	wrapper
+}
+
+// wrapper forwards optional interfaces to the wrapped decorator, so
+// bar options like BarClock reach it through the wrapper. Complete
+// message is kept by wrapper itself, if wrapped decorator doesn't
+// implement OnCompleteMessenger.
+type wrapper struct {
+	Decorator
+	completeMsg *string
+}
+
+func (d *wrapper) Decor(st *Statistics) string {
+	if st.Completed && d.completeMsg != nil {
+		return d.FormatMsg(*d.completeMsg)
+	}
+	return d.Decorator.Decor(st)
+}
+
+// FormatMsg formats msg by wrapped decorator's WC, so wrappers can be
+// nested. Otherwise msg is returned as is, still wrapped decorator's
+// width sync channel is fed, so synced column never waits for it.
+func (d *wrapper) FormatMsg(msg string) string {
+	if f, ok := d.Decorator.(interface{ FormatMsg(string) string }); ok {
+		return f.FormatMsg(msg)
+	}
+	if ch, ok := d.Decorator.Sync(); ok {
+		ch <- internal.StringWidth(msg)
+		<-ch
+	}
+	return msg
+}
+
+func (d *wrapper) OnCompleteMessage(msg string) {
+	if cm, ok := d.Decorator.(OnCompleteMessenger); ok {
+		cm.OnCompleteMessage(msg)
+		return
+	}
+	d.completeMsg = &msg
+}
+
+func (d *wrapper) NextAmount(n int64, wdd ...time.Duration) {
+	if ar, ok := d.Decorator.(AmountReceiver); ok {
+		ar.NextAmount(n, wdd...)
+	}
+}
+
+func (d *wrapper) Shutdown() {
+	if sl, ok := d.Decorator.(ShutdownListener); ok {
+		sl.Shutdown()
+	}
+}
+
+func (d *wrapper) SetRefreshInterval(interval time.Duration) {
+	if rl, ok := d.Decorator.(RefreshLimiter); ok {
+		rl.SetRefreshInterval(interval)
+	}
+}
+
+func (d *wrapper) SetClock(now func() time.Time) {
+	if c, ok := d.Decorator.(Clocked); ok {
+		c.SetClock(now)
+	}
+}
+
+func (d *wrapper) SetStartTime(t time.Time) {
+	if ss, ok := d.Decorator.(StartTimeSetter); ok {
+		ss.SetStartTime(t)
+	}
+}
+
+func (d *wrapper) ResetETA() {
+	if er, ok := d.Decorator.(ETAResetter); ok {
+		er.ResetETA()
+	}
+}
+
+func (d *wrapper) SetEwmaAlpha(alpha float64) {
+	if es, ok := d.Decorator.(EwmaAlphaSetter); ok {
+		es.SetEwmaAlpha(alpha)
 	}
-	return decorator
 }
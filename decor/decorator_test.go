@@ -0,0 +1,25 @@
+package decor
+
+import "testing"
+
+func TestFormatMsgWide(t *testing.T) {
+	tests := map[string]struct {
+		wc   WC
+		msg  string
+		want string
+	}{
+		"ascii":      {WC{W: 6}, "abcd", "  abcd"},
+		"wide":       {WC{W: 6}, "进度", "  进度"},
+		"wide right": {WC{W: 6, C: DidentRight}, "进度", "进度  "},
+		"wide as is": {WC{W: 3}, "进度", "进度"},
+		"odd width":  {WC{W: 5}, "进度", " 进度"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc.wc.Init()
+			if got := tc.wc.FormatMsg(tc.msg); got != tc.want {
+				t.Errorf("Want: %q, Got: %q\n", tc.want, got)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ type elapsedDecorator struct {
 	WC
 	style       TimeStyle
 	startTime   time.Time
+	clock       clock
 	msg         string
 	completeMsg *string
 }
@@ -40,7 +41,7 @@ func (d *elapsedDecorator) Decor(st *Statistics) string {
 		return d.FormatMsg(d.msg)
 	}
 
-	timeElapsed := time.Since(d.startTime)
+	timeElapsed := d.clock.now().Sub(d.startTime)
 	hours := int64((timeElapsed / time.Hour) % 60)
 	minutes := int64((timeElapsed / time.Minute) % 60)
 	seconds := int64((timeElapsed / time.Second) % 60)
@@ -66,3 +67,12 @@ func (d *elapsedDecorator) Decor(st *Statistics) string {
 func (d *elapsedDecorator) OnCompleteMessage(msg string) {
 	d.completeMsg = &msg
 }
+
+func (d *elapsedDecorator) SetClock(now func() time.Time) {
+	d.clock = now
+	d.startTime = d.clock.now()
+}
+
+func (d *elapsedDecorator) SetStartTime(t time.Time) {
+	d.startTime = t
+}
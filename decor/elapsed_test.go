@@ -0,0 +1,20 @@
+package decor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestElapsedClock(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := Elapsed(ET_STYLE_MMSS)
+	d.(Clocked).SetClock(func() time.Time { return now })
+
+	now = now.Add(90 * time.Second)
+
+	want := "01:30"
+	got := d.Decor(new(Statistics))
+	if got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
@@ -0,0 +1,24 @@
+package decor
+
+// OnError returns decorator, which wraps provided decorator, with sole
+// purpose to display error message, once error has been set with
+// Bar.SetError. Width config of wrapped decorator applies to the
+// message as well.
+//
+//	`decorator` Decorator to wrap
+func OnError(decorator Decorator) Decorator {
+	return &onErrorWrapper{
+		wrapper: wrapper{Decorator: decorator},
+	}
+}
+
+type onErrorWrapper struct {
+	wrapper
+}
+
+func (d *onErrorWrapper) Decor(st *Statistics) string {
+	if st.Err != nil {
+		return d.FormatMsg(st.Err.Error())
+	}
+	return d.wrapper.Decor(st)
+}
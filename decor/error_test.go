@@ -0,0 +1,51 @@
+package decor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWrapperForwarding(t *testing.T) {
+	wrappers := map[string]func(Decorator) Decorator{
+		"OnError": OnError,
+		"OnComplete": func(d Decorator) Decorator {
+			msg := "done"
+			return &onCompleteWrapper{wrapper{Decorator: d, completeMsg: &msg}}
+		},
+	}
+
+	for name, wrap := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			d := wrap(AverageETA(ET_STYLE_GO))
+			d.(Clocked).SetClock(func() time.Time { return now })
+			d.(StartTimeSetter).SetStartTime(now)
+
+			// 10 items in 10s, 90 items left
+			now = now.Add(10 * time.Second)
+			got := d.Decor(&Statistics{Total: 100, Current: 10})
+			if want := "1m30s"; got != want {
+				t.Errorf("Want: %q, Got: %q\n", want, got)
+			}
+
+			// rollback to 5, prior measurements are discarded
+			d.(ETAResetter).ResetETA()
+			d.Decor(&Statistics{Total: 100, Current: 5})
+			now = now.Add(time.Second)
+			got = d.Decor(&Statistics{Total: 100, Current: 15})
+			if want := "8s"; got != want {
+				t.Errorf("Want: %q, Got: %q\n", want, got)
+			}
+
+			// refresh limit reaches wrapped decorator as well
+			d.(RefreshLimiter).SetRefreshInterval(time.Minute)
+			now = now.Add(time.Second)
+			d.Decor(&Statistics{Total: 100, Current: 20})
+			now = now.Add(time.Second)
+			got = d.Decor(&Statistics{Total: 100, Current: 50})
+			if cached := d.Decor(&Statistics{Total: 100, Current: 90}); cached != got {
+				t.Errorf("Want cached: %q, Got: %q\n", got, cached)
+			}
+		})
+	}
+}
@@ -30,7 +30,11 @@ func (f TimeNormalizerFunc) Normalize(src time.Duration) time.Duration {
 //
 //	`wcc` optional WC config
 func EwmaETA(style TimeStyle, age float64, wcc ...WC) Decorator {
-	return MovingAverageETA(style, ewma.NewMovingAverage(age), nil, wcc...)
+	d := MovingAverageETA(style, ewma.NewMovingAverage(age), nil, wcc...)
+	d.(*movingAverageETA).newAverage = func() ewma.MovingAverage {
+		return ewma.NewMovingAverage(age)
+	}
+	return d
 }
 
 // MovingAverageETA decorator relies on MovingAverage implementation to calculate its average.
@@ -63,12 +67,19 @@ type movingAverageETA struct {
 	average     ewma.MovingAverage
 	completeMsg *string
 	normalizer  TimeNormalizer
+	refresh     refreshLimit
+	// newAverage is set by EwmaETA, custom MovingAverage can't be
+	// recreated
+	newAverage func() ewma.MovingAverage
 }
 
 func (d *movingAverageETA) Decor(st *Statistics) string {
 	if st.Completed && d.completeMsg != nil {
 		return d.FormatMsg(*d.completeMsg)
 	}
+	if msg, ok := d.refresh.cached(); ok {
+		return d.FormatMsg(msg)
+	}
 
 	v := math.Round(d.average.Value())
 	remaining := time.Duration((st.Total - st.Current) * int64(v))
@@ -95,10 +106,11 @@ func (d *movingAverageETA) Decor(st *Statistics) string {
 		}
 	}
 
+	d.refresh.update(str)
 	return d.FormatMsg(str)
 }
 
-func (d *movingAverageETA) NextAmount(n int, wdd ...time.Duration) {
+func (d *movingAverageETA) NextAmount(n int64, wdd ...time.Duration) {
 	var workDuration time.Duration
 	for _, wd := range wdd {
 		workDuration = wd
@@ -114,6 +126,41 @@ func (d *movingAverageETA) OnCompleteMessage(msg string) {
 	d.completeMsg = &msg
 }
 
+func (d *movingAverageETA) SetRefreshInterval(interval time.Duration) {
+	d.refresh.interval = interval
+}
+
+// SetClock replaces time source of refresh limit, see
+// SetRefreshInterval.
+func (d *movingAverageETA) SetClock(now func() time.Time) {
+	d.refresh.clock = now
+}
+
+// ResetETA starts EwmaETA's average over, warmup included. Custom
+// MovingAverage is set to zero, as it can't be recreated.
+func (d *movingAverageETA) ResetETA() {
+	if d.newAverage != nil {
+		d.average = d.newAverage()
+	} else {
+		d.average.Set(0)
+	}
+	d.refresh.reset()
+}
+
+// SetEwmaAlpha is effective for EwmaETA only, as custom MovingAverage
+// can't be replaced safely.
+func (d *movingAverageETA) SetEwmaAlpha(alpha float64) {
+	if d.newAverage == nil {
+		return
+	}
+	// ewma decay is 2/(age+1)
+	age := 2/alpha - 1
+	d.newAverage = func() ewma.MovingAverage {
+		return ewma.NewMovingAverage(age)
+	}
+	d.average = d.newAverage()
+}
+
 // AverageETA decorator.
 //
 //	`style` one of [ET_STYLE_GO|ET_STYLE_HHMMSS|ET_STYLE_HHMM|ET_STYLE_MMSS]
@@ -137,17 +184,31 @@ type averageETA struct {
 	WC
 	style       TimeStyle
 	startTime   time.Time
+	clock       clock
 	completeMsg *string
+	refresh     refreshLimit
+	// progress done before ResetETA, it's excluded from average
+	base         int64
+	resetPending bool
 }
 
 func (d *averageETA) Decor(st *Statistics) string {
 	if st.Completed && d.completeMsg != nil {
 		return d.FormatMsg(*d.completeMsg)
 	}
+	if msg, ok := d.refresh.cached(); ok {
+		return d.FormatMsg(msg)
+	}
+
+	if d.resetPending {
+		d.base = st.Current
+		d.startTime = d.clock.now()
+		d.resetPending = false
+	}
 
 	var str string
-	timeElapsed := time.Since(d.startTime)
-	v := math.Round(float64(timeElapsed) / float64(st.Current))
+	timeElapsed := d.clock.now().Sub(d.startTime)
+	v := math.Round(float64(timeElapsed) / float64(st.Current-d.base))
 	if math.IsInf(v, 0) || math.IsNaN(v) {
 		v = 0
 	}
@@ -171,6 +232,7 @@ func (d *averageETA) Decor(st *Statistics) string {
 		}
 	}
 
+	d.refresh.update(str)
 	return d.FormatMsg(str)
 }
 
@@ -178,6 +240,52 @@ func (d *averageETA) OnCompleteMessage(msg string) {
 	d.completeMsg = &msg
 }
 
+func (d *averageETA) ResetETA() {
+	d.resetPending = true
+	d.refresh.reset()
+}
+
+func (d *averageETA) SetRefreshInterval(interval time.Duration) {
+	d.refresh.interval = interval
+}
+
+func (d *averageETA) SetClock(now func() time.Time) {
+	d.clock = now
+	d.startTime = d.clock.now()
+	d.refresh.clock = now
+}
+
+func (d *averageETA) SetStartTime(t time.Time) {
+	d.startTime = t
+}
+
+// refreshLimit holds last displayed message, until interval elapses.
+type refreshLimit struct {
+	interval time.Duration
+	clock    clock
+	last     time.Time
+	msg      string
+}
+
+// reset drops cached message, so next one is displayed right away.
+func (r *refreshLimit) reset() {
+	r.last = time.Time{}
+}
+
+func (r *refreshLimit) cached() (string, bool) {
+	if r.interval <= 0 || r.last.IsZero() {
+		return "", false
+	}
+	return r.msg, r.clock.now().Sub(r.last) < r.interval
+}
+
+func (r *refreshLimit) update(msg string) {
+	if r.interval > 0 {
+		r.msg = msg
+		r.last = r.clock.now()
+	}
+}
+
 func MaxTolerateTimeNormalizer(maxTolerate time.Duration) TimeNormalizer {
 	var normalized time.Duration
 	var lastCall time.Time
@@ -0,0 +1,80 @@
+package decor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAverageETAReset(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := AverageETA(ET_STYLE_GO)
+	d.(Clocked).SetClock(func() time.Time { return now })
+
+	// 10 items in 10s, 90 items left
+	now = now.Add(10 * time.Second)
+	got := d.Decor(&Statistics{Total: 100, Current: 10})
+	if want := "1m30s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+
+	// rollback to 5, prior measurements are discarded
+	d.(ETAResetter).ResetETA()
+	d.Decor(&Statistics{Total: 100, Current: 5})
+	now = now.Add(time.Second)
+	got = d.Decor(&Statistics{Total: 100, Current: 15})
+	if want := "8s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestEwmaETAReset(t *testing.T) {
+	d := EwmaETA(ET_STYLE_GO, 10)
+
+	// warm up with 1s per item
+	for i := 0; i < 20; i++ {
+		d.(AmountReceiver).NextAmount(1, time.Second)
+	}
+	got := d.Decor(&Statistics{Total: 100, Current: 90})
+	if want := "10s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+
+	// after reset, new rate isn't averaged against prior samples or zero
+	d.(ETAResetter).ResetETA()
+	for i := 0; i < 20; i++ {
+		d.(AmountReceiver).NextAmount(1, 100*time.Millisecond)
+	}
+	got = d.Decor(&Statistics{Total: 100, Current: 0})
+	if want := "10s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestETARefreshInterval(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := EwmaETA(ET_STYLE_GO, 0)
+	d.(Clocked).SetClock(func() time.Time { return now })
+	d.(RefreshLimiter).SetRefreshInterval(time.Minute)
+
+	// 1s per item, past ewma warm up
+	for i := 0; i < 20; i++ {
+		d.(AmountReceiver).NextAmount(1, time.Second)
+	}
+	got := d.Decor(&Statistics{Total: 100, Current: 90})
+	if want := "10s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+
+	// estimate changes, displayed value is held within interval
+	now = now.Add(30 * time.Second)
+	got = d.Decor(&Statistics{Total: 100, Current: 95})
+	if want := "10s"; got != want {
+		t.Errorf("Want held: %q, Got: %q\n", want, got)
+	}
+
+	now = now.Add(30 * time.Second)
+	got = d.Decor(&Statistics{Total: 100, Current: 95})
+	if want := "5s"; got != want {
+		t.Errorf("Want updated: %q, Got: %q\n", want, got)
+	}
+}
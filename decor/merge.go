@@ -0,0 +1,110 @@
+package decor
+
+import (
+	"strings"
+	"time"
+)
+
+// Merge decorator concatenates outputs of provided decorators, so they
+// take part in width synchronization as a single column, configured by
+// wc. Width sync of merged decorators themselves is bypassed, each one
+// is formatted according to its own WC.W only.
+//
+//	`wc` WC config of the merged column
+//
+//	`decorators` decorators to merge, in order of appearance
+func Merge(wc WC, decorators ...Decorator) Decorator {
+	wc.Init()
+	d := &mergeDecorator{
+		WC:         wc,
+		decorators: decorators,
+	}
+	return d
+}
+
+type mergeDecorator struct {
+	WC
+	decorators  []Decorator
+	completeMsg *string
+}
+
+func (d *mergeDecorator) Decor(st *Statistics) string {
+	if st.Completed && d.completeMsg != nil {
+		return d.FormatMsg(*d.completeMsg)
+	}
+	return d.FormatMsg(d.concat(st))
+}
+
+// concat returns outputs of merged decorators, without formatting them
+// as a whole.
+func (d *mergeDecorator) concat(st *Statistics) string {
+	var b strings.Builder
+	for _, decorator := range d.decorators {
+		if ch, ok := decorator.Sync(); ok {
+			// nobody else syncs with merged decorator, echo its own width
+			go func() { ch <- <-ch }()
+		}
+		b.WriteString(decorator.Decor(st))
+	}
+	return b.String()
+}
+
+func (d *mergeDecorator) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
+
+func (d *mergeDecorator) NextAmount(n int64, wdd ...time.Duration) {
+	for _, decorator := range d.decorators {
+		if ar, ok := decorator.(AmountReceiver); ok {
+			ar.NextAmount(n, wdd...)
+		}
+	}
+}
+
+func (d *mergeDecorator) Shutdown() {
+	for _, decorator := range d.decorators {
+		if sl, ok := decorator.(ShutdownListener); ok {
+			sl.Shutdown()
+		}
+	}
+}
+
+func (d *mergeDecorator) SetRefreshInterval(interval time.Duration) {
+	for _, decorator := range d.decorators {
+		if rl, ok := decorator.(RefreshLimiter); ok {
+			rl.SetRefreshInterval(interval)
+		}
+	}
+}
+
+func (d *mergeDecorator) SetClock(now func() time.Time) {
+	for _, decorator := range d.decorators {
+		if c, ok := decorator.(Clocked); ok {
+			c.SetClock(now)
+		}
+	}
+}
+
+func (d *mergeDecorator) SetStartTime(t time.Time) {
+	for _, decorator := range d.decorators {
+		if ss, ok := decorator.(StartTimeSetter); ok {
+			ss.SetStartTime(t)
+		}
+	}
+}
+
+func (d *mergeDecorator) ResetETA() {
+	for _, decorator := range d.decorators {
+		if er, ok := decorator.(ETAResetter); ok {
+			er.ResetETA()
+		}
+	}
+}
+
+func (d *mergeDecorator) SetEwmaAlpha(alpha float64) {
+	for _, decorator := range d.decorators {
+		if es, ok := decorator.(EwmaAlphaSetter); ok {
+			es.SetEwmaAlpha(alpha)
+		}
+	}
+}
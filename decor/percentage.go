@@ -2,27 +2,87 @@ package decor
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/vbauerster/mpb/v4/internal"
 )
 
-// Percentage returns percentage decorator.
+type percentageType float64
+
+func (s percentageType) Format(st fmt.State, verb rune) {
+	var prec int
+	if verb == 'd' {
+		// same rounding as of internal.Percentage, i.e. half up
+		s = percentageType(math.Round(float64(s)))
+	} else if p, ok := st.Precision(); ok {
+		prec = p
+	} else {
+		prec = 6
+	}
+
+	str := strconv.FormatFloat(float64(s), 'f', prec, 64)
+
+	if st.Flag(' ') {
+		str += " "
+	}
+	str += "%"
+
+	if w, ok := st.Width(); ok {
+		if pad := w - len(str); pad > 0 {
+			if st.Flag('-') {
+				str += strings.Repeat(" ", pad)
+			} else {
+				str = strings.Repeat(" ", pad) + str
+			}
+		}
+	}
+
+	io.WriteString(st, str)
+}
+
+// Percentage returns percentage decorator. It's a wrapper of
+// NewPercentage, formatting like "42 %".
+func Percentage(wcc ...WC) Decorator {
+	return NewPercentage("% d", wcc...)
+}
+
+// NewPercentage percentage decorator with custom format string.
+//
+//	`format` printf compatible verb, like "%d" or "% .1f"
 //
 //	`wcc` optional WC config
-func Percentage(wcc ...WC) Decorator {
+//
+// format examples:
+//
+//	format="%.1f"  output: "1.0%"
+//	format="% .1f" output: "1.0 %"
+//	format="%d"    output: "1%"
+//	format="% d"   output: "1 %"
+//
+// Value is right aligned within synced column, unless DidentRight is
+// set.
+func NewPercentage(format string, wcc ...WC) Decorator {
 	var wc WC
 	for _, widthConf := range wcc {
 		wc = widthConf
 	}
 	wc.Init()
+	if format == "" {
+		format = "% d"
+	}
 	d := &percentageDecorator{
-		WC: wc,
+		WC:     wc,
+		format: format,
 	}
 	return d
 }
 
 type percentageDecorator struct {
 	WC
+	format      string
 	completeMsg *string
 }
 
@@ -30,8 +90,8 @@ func (d *percentageDecorator) Decor(st *Statistics) string {
 	if st.Completed && d.completeMsg != nil {
 		return d.FormatMsg(*d.completeMsg)
 	}
-	str := fmt.Sprintf("%d %%", internal.Percentage(st.Total, st.Current, 100))
-	return d.FormatMsg(str)
+	p := internal.PercentageRaw(st.Total, st.Current, 100)
+	return d.FormatMsg(fmt.Sprintf(d.format, percentageType(p)))
 }
 
 func (d *percentageDecorator) OnCompleteMessage(msg string) {
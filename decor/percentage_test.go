@@ -0,0 +1,33 @@
+package decor
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPercentageType(t *testing.T) {
+	cases := map[string]struct {
+		value    float64
+		verb     string
+		expected string
+	}{
+		"10 %d":     {10, "%d", "10%"},
+		"10 % d":    {10, "% d", "10 %"},
+		"10 %f":     {10, "%f", "10.000000%"},
+		"10 %.1f":   {10, "%.1f", "10.0%"},
+		"10 % .1f":  {10, "% .1f", "10.0 %"},
+		"12.5 %d":   {12.5, "%d", "13%"},
+		"10 %6d":    {10, "%6d", "   10%"},
+		"10 %-6d":   {10, "%-6d", "10%   "},
+		"10 % 7d":   {10, "% 7d", "   10 %"},
+		"10 %-7.1f": {10, "%-7.1f", "10.0%  "},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := fmt.Sprintf(tc.verb, percentageType(tc.value))
+			if got != tc.expected {
+				t.Fatalf("expected: %q, got: %q\n", tc.expected, got)
+			}
+		})
+	}
+}
@@ -192,7 +192,7 @@ func (d *movingAverageSpeed) Decor(st *Statistics) string {
 	return d.FormatMsg(d.msg)
 }
 
-func (d *movingAverageSpeed) NextAmount(n int, wdd ...time.Duration) {
+func (d *movingAverageSpeed) NextAmount(n int64, wdd ...time.Duration) {
 	var workDuration time.Duration
 	for _, wd := range wdd {
 		workDuration = wd
@@ -239,6 +239,7 @@ type averageSpeed struct {
 	unit        int
 	unitFormat  string
 	startTime   time.Time
+	clock       clock
 	msg         string
 	completeMsg *string
 }
@@ -251,7 +252,7 @@ func (d *averageSpeed) Decor(st *Statistics) string {
 		return d.FormatMsg(d.msg)
 	}
 
-	timeElapsed := time.Since(d.startTime)
+	timeElapsed := d.clock.now().Sub(d.startTime)
 	speed := float64(st.Current) / timeElapsed.Seconds()
 
 	switch d.unit {
@@ -269,3 +270,220 @@ func (d *averageSpeed) Decor(st *Statistics) string {
 func (d *averageSpeed) OnCompleteMessage(msg string) {
 	d.completeMsg = &msg
 }
+
+func (d *averageSpeed) SetClock(now func() time.Time) {
+	d.clock = now
+	d.startTime = d.clock.now()
+}
+
+func (d *averageSpeed) SetStartTime(t time.Time) {
+	d.startTime = t
+}
+
+// SpeedWindow decorator with dynamic unit measure adjustment. Speed is
+// averaged over the last n render cycles. Decorator keeps samples
+// between calls, so it must not be shared among bars.
+//
+//	`n` number of render cycles to average over
+//
+//	`unit` one of [0|UnitKiB|UnitKB] zero for no unit
+//
+//	`unitFormat` printf compatible verb for value, like "%f" or "%d"
+//
+//	`wcc` optional WC config
+func SpeedWindow(n int, unit int, unitFormat string, wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	if n < 1 {
+		n = 1
+	}
+	d := &windowSpeed{
+		WC:         wc,
+		unit:       unit,
+		unitFormat: unitFormat,
+		samples:    make([]speedSample, 0, n+1),
+	}
+	return d
+}
+
+type speedSample struct {
+	time    time.Time
+	current int64
+}
+
+type windowSpeed struct {
+	WC
+	unit        int
+	unitFormat  string
+	samples     []speedSample
+	clock       clock
+	msg         string
+	completeMsg *string
+}
+
+func (d *windowSpeed) Decor(st *Statistics) string {
+	if st.Completed {
+		if d.completeMsg != nil {
+			return d.FormatMsg(*d.completeMsg)
+		}
+		return d.FormatMsg(d.msg)
+	}
+
+	if len(d.samples) == cap(d.samples) {
+		d.samples = append(d.samples[:0], d.samples[1:]...)
+	}
+	d.samples = append(d.samples, speedSample{d.clock.now(), st.Current})
+
+	var speed float64
+	first, last := d.samples[0], d.samples[len(d.samples)-1]
+	if elapsed := last.time.Sub(first.time).Seconds(); elapsed > 0 {
+		speed = float64(last.current-first.current) / elapsed
+	}
+
+	switch d.unit {
+	case UnitKiB:
+		d.msg = fmt.Sprintf(d.unitFormat, SpeedKiB(speed))
+	case UnitKB:
+		d.msg = fmt.Sprintf(d.unitFormat, SpeedKB(speed))
+	default:
+		d.msg = fmt.Sprintf(d.unitFormat, speed)
+	}
+
+	return d.FormatMsg(d.msg)
+}
+
+func (d *windowSpeed) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
+
+func (d *windowSpeed) SetClock(now func() time.Time) {
+	d.clock = now
+	d.samples = d.samples[:0]
+}
+
+// ItemRate decorator displays average count of items per second, as
+// reported by Bar.IncrWeighted, so throughput of items of varying cost
+// is shown independent of weighted progress.
+//
+//	`format` printf compatible verb for value, like "%.1f items/s"
+//
+//	`wcc` optional WC config
+func ItemRate(format string, wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	d := &itemRate{
+		WC:        wc,
+		format:    format,
+		startTime: time.Now(),
+	}
+	return d
+}
+
+type itemRate struct {
+	WC
+	format      string
+	startTime   time.Time
+	clock       clock
+	msg         string
+	completeMsg *string
+}
+
+func (d *itemRate) Decor(st *Statistics) string {
+	if st.Completed {
+		if d.completeMsg != nil {
+			return d.FormatMsg(*d.completeMsg)
+		}
+		return d.FormatMsg(d.msg)
+	}
+
+	var rate float64
+	if timeElapsed := d.clock.now().Sub(d.startTime); timeElapsed > 0 {
+		rate = float64(st.Items) / timeElapsed.Seconds()
+	}
+	d.msg = fmt.Sprintf(d.format, rate)
+
+	return d.FormatMsg(d.msg)
+}
+
+func (d *itemRate) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
+
+func (d *itemRate) SetClock(now func() time.Time) {
+	d.clock = now
+	d.startTime = d.clock.now()
+}
+
+func (d *itemRate) SetStartTime(t time.Time) {
+	d.startTime = t
+}
+
+// SecondsPerItem decorator displays ewma of work duration per item,
+// like "2.3s/item", which reads better than rate for slow items. Work
+// duration is taken from IncrBy's wdd, "-" is displayed until it has
+// been provided at least once.
+//
+//	`wcc` optional WC config
+func SecondsPerItem(wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	d := &secondsPerItem{
+		WC:      wc,
+		average: ewma.NewMovingAverage(),
+	}
+	return d
+}
+
+type secondsPerItem struct {
+	WC
+	average     ewma.MovingAverage
+	msg         string
+	completeMsg *string
+}
+
+func (d *secondsPerItem) Decor(st *Statistics) string {
+	if st.Completed {
+		if d.completeMsg != nil {
+			return d.FormatMsg(*d.completeMsg)
+		}
+		return d.FormatMsg(d.msg)
+	}
+
+	perItem := time.Duration(d.average.Value())
+	switch {
+	case perItem <= 0:
+		d.msg = "-"
+	case perItem >= time.Second:
+		d.msg = perItem.Round(100*time.Millisecond).String() + "/item"
+	case perItem >= time.Millisecond:
+		d.msg = perItem.Round(100*time.Microsecond).String() + "/item"
+	default:
+		d.msg = perItem.Round(time.Microsecond).String() + "/item"
+	}
+
+	return d.FormatMsg(d.msg)
+}
+
+func (d *secondsPerItem) NextAmount(n int64, wdd ...time.Duration) {
+	var workDuration time.Duration
+	for _, wd := range wdd {
+		workDuration = wd
+	}
+	if n <= 0 || workDuration <= 0 {
+		return
+	}
+	d.average.Add(float64(workDuration) / float64(n))
+}
+
+func (d *secondsPerItem) OnCompleteMessage(msg string) {
+	d.completeMsg = &msg
+}
@@ -3,6 +3,7 @@ package decor
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestSpeedKiB(t *testing.T) {
@@ -140,3 +141,54 @@ func TestSpeedKB(t *testing.T) {
 		})
 	}
 }
+
+func TestSpeedWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := SpeedWindow(2, 0, "%.0f")
+	d.(Clocked).SetClock(func() time.Time { return now })
+
+	steps := []struct {
+		current int64
+		want    string
+	}{
+		{0, "0"},
+		{100, "100"},
+		{300, "150"},
+		{600, "250"},
+	}
+
+	for _, s := range steps {
+		got := d.Decor(&Statistics{Current: s.current})
+		if got != s.want {
+			t.Errorf("current %d: Want: %q, Got: %q\n", s.current, s.want, got)
+		}
+		now = now.Add(time.Second)
+	}
+}
+
+func TestItemRate(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := ItemRate("%.1f items/s")
+	d.(Clocked).SetClock(func() time.Time { return now })
+
+	now = now.Add(4 * time.Second)
+	got := d.Decor(&Statistics{Current: 1000, Items: 10})
+	if want := "2.5 items/s"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
+
+func TestSecondsPerItem(t *testing.T) {
+	d := SecondsPerItem()
+
+	got := d.Decor(new(Statistics))
+	if want := "-"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+
+	d.(AmountReceiver).NextAmount(2, 4600*time.Millisecond)
+	got = d.Decor(&Statistics{Current: 2})
+	if want := "2.3s/item"; got != want {
+		t.Errorf("Want: %q, Got: %q\n", want, got)
+	}
+}
@@ -0,0 +1,61 @@
+package decor
+
+import "github.com/vbauerster/mpb/v4/internal"
+
+// Truncate decorator cuts output of provided decorator to at most
+// maxWidth runes, ending cut output with ellipsis, so long dynamic
+// labels don't break the layout. Width sync, if any, is configured by
+// wcc and is based on the truncated width. Width sync of the wrapped
+// decorator itself is bypassed, like with Merge.
+//
+//	`decorator` Decorator to truncate
+//
+//	`maxWidth` max width of output, including ellipsis
+//
+//	`ellipsis` marker of cut output, e.g. "…"
+//
+//	`wcc` optional WC config
+func Truncate(decorator Decorator, maxWidth int, ellipsis string, wcc ...WC) Decorator {
+	var wc WC
+	for _, widthConf := range wcc {
+		wc = widthConf
+	}
+	wc.Init()
+	d := &truncateDecorator{
+		mergeDecorator: &mergeDecorator{
+			WC:         wc,
+			decorators: []Decorator{decorator},
+		},
+		maxWidth: maxWidth,
+		ellipsis: ellipsis,
+	}
+	return d
+}
+
+type truncateDecorator struct {
+	*mergeDecorator
+	maxWidth int
+	ellipsis string
+}
+
+func (d *truncateDecorator) Decor(st *Statistics) string {
+	var msg string
+	if st.Completed && d.completeMsg != nil {
+		msg = *d.completeMsg
+	} else {
+		msg = d.concat(st)
+	}
+	return d.FormatMsg(truncate(msg, d.maxWidth, d.ellipsis))
+}
+
+func truncate(msg string, maxWidth int, ellipsis string) string {
+	if internal.StringWidth(msg) <= maxWidth {
+		return msg
+	}
+	n := maxWidth - internal.StringWidth(ellipsis)
+	if n < 0 {
+		// no room even for ellipsis
+		return internal.Truncate(msg, maxWidth)
+	}
+	return internal.Truncate(msg, n) + ellipsis
+}
@@ -0,0 +1,27 @@
+package decor
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	cases := map[string]struct {
+		name     string
+		maxWidth int
+		ellipsis string
+		expected string
+	}{
+		"fits":          {"short", 5, "…", "short"},
+		"cut":           {"very long label", 8, "…", "very lo…"},
+		"cut multibyte": {"привет мир", 7, "...", "прив..."},
+		"no ellipsis":   {"very long label", 4, "", "very"},
+		"no room":       {"very long label", 2, "...", "ve"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := Truncate(Name(tc.name), tc.maxWidth, tc.ellipsis)
+			got := d.Decor(new(Statistics))
+			if got != tc.expected {
+				t.Fatalf("expected: %q, got: %q\n", tc.expected, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package mpb_test
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 
@@ -177,6 +178,156 @@ func TestPercentageDSyncSpace(t *testing.T) {
 	testDecoratorConcurrently(t, testCases)
 }
 
+func TestPercentageDwidthSyncMinWidth(t *testing.T) {
+
+	testCases := [][]step{
+		[]step{
+			{
+				&decor.Statistics{Total: 100, Current: 8},
+				decor.Percentage(decor.WC{W: 5, C: decor.DSyncWidth}),
+				"  8 %",
+			},
+			{
+				&decor.Statistics{Total: 100, Current: 10},
+				decor.Percentage(decor.WC{W: 5, C: decor.DSyncWidth}),
+				" 10 %",
+			},
+		},
+		[]step{
+			{
+				&decor.Statistics{Total: 100, Current: 9},
+				decor.Percentage(decor.WC{W: 5, C: decor.DSyncWidth}),
+				"  9 %",
+			},
+			{
+				&decor.Statistics{Total: 100, Current: 100},
+				decor.Percentage(decor.WC{W: 5, C: decor.DSyncWidth}),
+				"100 %",
+			},
+		},
+	}
+
+	testDecoratorConcurrently(t, testCases)
+}
+
+func TestAnyDSyncSpace(t *testing.T) {
+	fn := func(st *decor.Statistics) string {
+		return fmt.Sprintf("%d/%d", st.Current, st.Total)
+	}
+
+	testCases := [][]step{
+		[]step{
+			{
+				&decor.Statistics{Total: 100, Current: 8},
+				decor.Any(fn, decor.WCSyncSpace),
+				" 8/100",
+			},
+			{
+				&decor.Statistics{Total: 100, Current: 9},
+				decor.Any(fn, decor.WCSyncSpace),
+				" 9/100",
+			},
+		},
+		[]step{
+			{
+				&decor.Statistics{Total: 100, Current: 9},
+				decor.Any(fn, decor.WCSyncSpace),
+				"   9/100",
+			},
+			{
+				&decor.Statistics{Total: 100, Current: 100},
+				decor.Any(fn, decor.WCSyncSpace),
+				" 100/100",
+			},
+		},
+	}
+
+	testDecoratorConcurrently(t, testCases)
+}
+
+func TestMergeDSyncWidth(t *testing.T) {
+	merged := func(name string) decor.Decorator {
+		return decor.Merge(decor.WCSyncWidth,
+			decor.Percentage(decor.WCSyncWidth),
+			decor.Name(" • "),
+			decor.Name(name),
+		)
+	}
+
+	testCases := [][]step{
+		[]step{
+			{
+				&decor.Statistics{Total: 100, Current: 9},
+				merged("a"),
+				"    9 % • a",
+			},
+			{
+				&decor.Statistics{Total: 100, Current: 100},
+				merged("bbb"),
+				"100 % • bbb",
+			},
+		},
+	}
+
+	testDecoratorConcurrently(t, testCases)
+}
+
+func TestColumnWidth(t *testing.T) {
+	short := decor.Name("a", decor.WCSyncSpace)
+	long := decor.Name("abcd", decor.WCSyncSpace)
+
+	if w := decor.ColumnWidth(short); w != 0 {
+		t.Errorf("Expected zero width before render, got: %d\n", w)
+	}
+
+	testDecoratorConcurrently(t, [][]step{
+		[]step{
+			{&decor.Statistics{}, short, "    a"},
+			{&decor.Statistics{}, long, " abcd"},
+		},
+	})
+
+	for _, d := range []decor.Decorator{short, long} {
+		if w := decor.ColumnWidth(d); w != 5 {
+			t.Errorf("Expected column width 5, got: %d\n", w)
+		}
+	}
+}
+
+func TestOnCompleteWrapsAnyDecorator(t *testing.T) {
+
+	testCases := [][]step{
+		[]step{
+			{
+				&decor.Statistics{Completed: false},
+				decor.OnComplete(newStaticDecorator("running", decor.WCSyncWidth), "done"),
+				"running",
+			},
+			{
+				&decor.Statistics{Completed: true},
+				decor.OnComplete(newStaticDecorator("running", decor.WCSyncWidth), "done"),
+				"   done",
+			},
+		},
+	}
+
+	testDecoratorConcurrently(t, testCases)
+}
+
+func newStaticDecorator(msg string, wc decor.WC) decor.Decorator {
+	wc.Init()
+	return &staticDecorator{WC: wc, msg: msg}
+}
+
+type staticDecorator struct {
+	decor.WC
+	msg string
+}
+
+func (d *staticDecorator) Decor(st *decor.Statistics) string {
+	return d.FormatMsg(d.msg)
+}
+
 func testDecoratorConcurrently(t *testing.T, testCases [][]step) {
 	if len(testCases) == 0 {
 		t.Fail()
@@ -2,8 +2,12 @@ package mpb
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
+
+	"github.com/vbauerster/mpb/v4/decor"
 )
 
 func TestDraw(t *testing.T) {
@@ -30,7 +34,7 @@ func TestDraw(t *testing.T) {
 				current:   20,
 				barWidth:  80,
 				trimSpace: true,
-				want:      "",
+				want:      "=-",
 			},
 		},
 		3: {
@@ -39,7 +43,7 @@ func TestDraw(t *testing.T) {
 				total:    60,
 				current:  20,
 				barWidth: 80,
-				want:     "  ",
+				want:     " - ",
 			},
 			{
 				name:      "t,c,bw,trim{60,20,80,true}",
@@ -47,7 +51,7 @@ func TestDraw(t *testing.T) {
 				current:   20,
 				barWidth:  80,
 				trimSpace: true,
-				want:      "",
+				want:      "[-]",
 			},
 		},
 		4: {
@@ -56,7 +60,7 @@ func TestDraw(t *testing.T) {
 				total:    60,
 				current:  20,
 				barWidth: 80,
-				want:     "  ",
+				want:     " =- ",
 			},
 			{
 				name:      "t,c,bw,trim{60,20,80,true}",
@@ -64,7 +68,7 @@ func TestDraw(t *testing.T) {
 				current:   20,
 				barWidth:  80,
 				trimSpace: true,
-				want:      "[]",
+				want:      "[>-]",
 			},
 		},
 		5: {
@@ -73,7 +77,7 @@ func TestDraw(t *testing.T) {
 				total:    60,
 				current:  20,
 				barWidth: 80,
-				want:     "  ",
+				want:     " [-] ",
 			},
 			{
 				name:      "t,c,bw,trim{60,20,80,true}",
@@ -90,7 +94,7 @@ func TestDraw(t *testing.T) {
 				total:    60,
 				current:  20,
 				barWidth: 80,
-				want:     " [] ",
+				want:     " [>-] ",
 			},
 			{
 				name:      "t,c,bw,trim{60,20,80,true}",
@@ -295,12 +299,247 @@ func TestDraw(t *testing.T) {
 	}
 }
 
+func TestFillTinyWidth(t *testing.T) {
+	tests := []struct {
+		width          int
+		total, current int64
+		want           string
+	}{
+		{0, 100, 50, ""},
+		{1, 100, 0, "-"},
+		{1, 100, 50, "="},
+		{1, 100, 100, "="},
+		{2, 100, 0, "--"},
+		{2, 100, 50, "=-"},
+		{2, 100, 100, "=="},
+		{3, 100, 0, "[-]"},
+		{3, 100, 50, "[=]"},
+		{3, 100, 100, "[=]"},
+	}
+
+	filler := newDefaultBarFiller()
+	for _, test := range tests {
+		var buf bytes.Buffer
+		filler.Fill(&buf, test.width, &decor.Statistics{Total: test.total, Current: test.current})
+		if got := buf.String(); got != test.want {
+			t.Errorf("width:%d current:%d want: %q, got: %q\n", test.width, test.current, test.want, got)
+		}
+	}
+}
+
+func TestDrawWrappedLines(t *testing.T) {
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.trimSpace = true
+	s.aDecorators = []decor.Decorator{decor.Name(strings.Repeat("x", 45))}
+
+	termWidth := 20
+	var buf bytes.Buffer
+	buf.ReadFrom(s.draw(termWidth))
+
+	lineWidth := utf8.RuneCount(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	want := (lineWidth - 1) / termWidth
+	if want == 0 || s.wrappedLines != want {
+		t.Errorf("line width %d at termWidth %d, want wrapped lines: %d, got: %d\n", lineWidth, termWidth, want, s.wrappedLines)
+	}
+}
+
+func TestDrawSpinnerPrefix(t *testing.T) {
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.trimSpace = true
+	BarSpinnerPrefix("-")(s)
+
+	var buf bytes.Buffer
+	buf.ReadFrom(s.draw(80))
+
+	want := "- [=======>--------]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want: %q, got: %q\n", want, got)
+	}
+}
+
+func TestDrawIndent(t *testing.T) {
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.trimSpace = true
+	s.indent = 4
+
+	var buf bytes.Buffer
+	buf.ReadFrom(s.draw(12))
+
+	want := "    [==>---]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want: %q, got: %q\n", want, got)
+	}
+}
+
+func TestDrawMinWidth(t *testing.T) {
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.minWidth = 5
+	s.pDecorators = []decor.Decorator{decor.Name("name")}
+	s.aDecorators = []decor.Decorator{decor.Name("50%")}
+
+	tests := []struct {
+		termWidth int
+		want      string
+	}{
+		{15, "name [=>--] 50%\n"},
+		{13, "name 50%\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		buf.ReadFrom(s.draw(test.termWidth))
+		if got := buf.String(); got != test.want {
+			t.Errorf("termWidth %d want: %q, got: %q\n", test.termWidth, test.want, got)
+		}
+		s.bufP.Reset()
+		s.bufB.Reset()
+		s.bufA.Reset()
+	}
+}
+
+func TestDrawMinWidthUnset(t *testing.T) {
+	s := newTestState()
+	s.width = 20
+	s.total = 100
+	s.current = 50
+	s.pDecorators = []decor.Decorator{decor.Name("name")}
+	s.aDecorators = []decor.Decorator{decor.Name("50%")}
+
+	// no room for filler, both edge spaces are kept as before
+	var buf bytes.Buffer
+	buf.ReadFrom(s.draw(8))
+	want := "name  50%\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want: %q, got: %q\n", want, got)
+	}
+}
+
+func TestDrawMinimal(t *testing.T) {
+	s := newTestState()
+	s.width = 10
+	s.total = 100
+	s.current = 50
+	s.indent = 2
+	s.minimal = true
+	s.pDecorators = []decor.Decorator{decor.Name("name")}
+	s.aDecorators = []decor.Decorator{decor.Name("50%")}
+
+	tests := []struct {
+		termWidth int
+		want      string
+	}{
+		{80, "[===>----]\n"},
+		{6, "[=>--]\n"},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		buf.ReadFrom(s.draw(test.termWidth))
+		if got := buf.String(); got != test.want {
+			t.Errorf("termWidth %d want: %q, got: %q\n", test.termWidth, test.want, got)
+		}
+		s.bufB.Reset()
+	}
+}
+
+func TestSpinnerFullWidth(t *testing.T) {
+	tests := []struct {
+		frames    []string
+		alignment SpinnerAlignment
+		width     int
+		want      string
+	}{
+		{VerticalSpinnerStyle, SpinnerOnLeft, 5, "▁▁▁▁▁"},
+		{[]string{"<>"}, SpinnerOnLeft, 5, "<><> "},
+		{[]string{"<>"}, SpinnerOnMiddle, 7, "<><><> "},
+		{[]string{"<>"}, SpinnerOnRight, 5, " <><>"},
+	}
+
+	for _, test := range tests {
+		f := &spinnerFiller{
+			frames:    test.frames,
+			alignment: test.alignment,
+			fullWidth: true,
+		}
+		var buf bytes.Buffer
+		f.Fill(&buf, test.width, new(decor.Statistics))
+		if got := buf.String(); got != test.want {
+			t.Errorf("want: %q, got: %q\n", test.want, got)
+		}
+	}
+}
+
+func TestBounceFill(t *testing.T) {
+	f := newBounceFiller().(*bounceFiller)
+	var buf bytes.Buffer
+	for _, completed := range []bool{false, true} {
+		for width := 4; width < 20; width++ {
+			f.startTime = time.Now().Add(-time.Duration(width) * bounceStep)
+			buf.Reset()
+			f.Fill(&buf, width, &decor.Statistics{Completed: completed})
+			if got := utf8.RuneCount(buf.Bytes()); got != width {
+				t.Errorf("width:%d completed:%t got width: %d %q\n", width, completed, got, buf.String())
+			}
+		}
+	}
+}
+
+func TestFillerClock(t *testing.T) {
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	stat := &decor.Statistics{Total: 100, Current: 50}
+
+	s := newTestState()
+	BarTipAnimation([]rune("ab"), 6*bounceStep)(s)
+	s.filler.(decor.Clocked).SetClock(clock)
+
+	bounce := newBounceFiller()
+	bounce.(decor.Clocked).SetClock(clock)
+
+	tests := []struct {
+		elapsed    time.Duration
+		wantBar    string
+		wantBounce string
+	}{
+		{0, "[===a----]", "[===------]"},
+		{6 * bounceStep, "[===b----]", "[------===]"},
+		{12 * bounceStep, "[===a----]", "[===------]"},
+	}
+
+	for _, test := range tests {
+		now = time.Unix(0, 0).Add(test.elapsed)
+		var buf bytes.Buffer
+		s.filler.Fill(&buf, 10, stat)
+		if got := buf.String(); got != test.wantBar {
+			t.Errorf("elapsed %s want bar: %q, got: %q\n", test.elapsed, test.wantBar, got)
+		}
+		buf.Reset()
+		bounce.Fill(&buf, 11, stat)
+		if got := buf.String(); got != test.wantBounce {
+			t.Errorf("elapsed %s want bounce: %q, got: %q\n", test.elapsed, test.wantBounce, got)
+		}
+	}
+}
+
 func newTestState() *bState {
 	s := &bState{
-		filler: newDefaultBarFiller(),
-		bufP:   new(bytes.Buffer),
-		bufB:   new(bytes.Buffer),
-		bufA:   new(bytes.Buffer),
+		filler:   newDefaultBarFiller(),
+		bufP:     new(bytes.Buffer),
+		bufB:     new(bytes.Buffer),
+		bufA:     new(bytes.Buffer),
+		lineTerm: "\n",
 	}
 	return s
 }
@@ -0,0 +1,64 @@
+package mpb
+
+import (
+	"math"
+
+	"github.com/vbauerster/mpb/v4/internal"
+)
+
+// BarEvent is a snapshot of bar's progress, which is emitted on each
+// refresh, if container is set up with WithJSONOutput option.
+// Speed is measured in units per second, ETA in seconds. Total of
+// dynamic bar isn't known yet, so it's reported as 0, with Dynamic set
+// and no Percent and ETA.
+type BarEvent struct {
+	ID      int     `json:"id"`
+	Current int64   `json:"current"`
+	Total   int64   `json:"total"`
+	Dynamic bool    `json:"dynamic,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	ETA     float64 `json:"eta,omitempty"`
+	Speed   float64 `json:"speed"`
+}
+
+// EventSink receives bar lifecycle events, see WithEventSink. Either
+// OnComplete or OnAbort is called once per bar, whichever comes first.
+// Per refresh progress is fed by WithMetricsHook instead.
+type EventSink interface {
+	OnAdd(id int)
+	OnComplete(id int)
+	OnAbort(id int)
+}
+
+func newBarEvent(s *bState) *BarEvent {
+	e := &BarEvent{
+		ID:      s.id,
+		Current: s.current,
+	}
+	e.Speed = float64(s.current) / s.clock().Sub(s.startTime).Seconds()
+	if math.IsInf(e.Speed, 0) || math.IsNaN(e.Speed) {
+		e.Speed = 0
+	}
+	if s.dynamic {
+		// total is just a placeholder
+		e.Dynamic = true
+		return e
+	}
+	e.Total = s.total
+	e.Percent = internal.PercentageRaw(s.total, s.current, 100)
+	if e.Speed > 0 {
+		e.ETA = float64(s.total-s.current) / e.Speed
+	}
+	return e
+}
+
+func (e *BarEvent) percent() float64 {
+	if e == nil {
+		return 0
+	}
+	return e.Percent
+}
+
+func (e *BarEvent) done() bool {
+	return e != nil && !e.Dynamic && e.Current >= e.Total
+}
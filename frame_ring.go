@@ -0,0 +1,28 @@
+package mpb
+
+// frameRing is a ring buffer of rendered frames, see WithFrameHistory.
+type frameRing struct {
+	buf  []string
+	next int
+	full bool
+}
+
+func (r *frameRing) push(frame string) {
+	r.buf[r.next] = frame
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// frames returns copy of kept frames, oldest first. Nil ring has no
+// frames.
+func (r *frameRing) frames() []string {
+	if r == nil {
+		return nil
+	}
+	if !r.full {
+		return append([]string(nil), r.buf[:r.next]...)
+	}
+	return append(append([]string(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
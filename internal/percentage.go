@@ -4,9 +4,13 @@ import "math"
 
 // Percentage is a helper function, to calculate percentage.
 func Percentage(total, current, width int64) int64 {
+	return int64(math.Round(PercentageRaw(total, current, width)))
+}
+
+// PercentageRaw is like Percentage, but without rounding.
+func PercentageRaw(total, current, width int64) float64 {
 	if total <= 0 {
 		return 0
 	}
-	p := float64(width*current) / float64(total)
-	return int64(math.Round(p))
+	return float64(width*current) / float64(total)
 }
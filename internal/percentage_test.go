@@ -71,3 +71,45 @@ func TestPercentage(t *testing.T) {
 		}
 	}
 }
+
+func TestPercentageRaw(t *testing.T) {
+	cases := []struct {
+		name                  string
+		total, current, width int64
+		expected              float64
+	}{
+		{"t,c,w{0,1,80}", 0, 1, 80, 0},
+		{"t,c,w{100,0,80}", 100, 0, 80, 0},
+		{"t,c,w{100,1,10}", 100, 1, 10, 0.1},
+		{"t,c,w{100,15,10}", 100, 15, 10, 1.5},
+		{"t,c,w{80,10,10}", 80, 10, 10, 1.25},
+		{"t,c,w{100,100,10}", 100, 100, 10, 10},
+	}
+
+	for _, tc := range cases {
+		got := PercentageRaw(tc.total, tc.current, tc.width)
+		if got != tc.expected {
+			t.Errorf("%s: Expected: %f, got: %f\n", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestPercentageRoundsHalfUp(t *testing.T) {
+	cases := []struct {
+		name                            string
+		total, current, width, expected int64
+	}{
+		{"t,c,w{1000,494,100}", 1000, 494, 100, 49},
+		{"t,c,w{1000,495,100}", 1000, 495, 100, 50},
+		{"t,c,w{1000,499,100}", 1000, 499, 100, 50},
+		{"t,c,w{1000,994,100}", 1000, 994, 100, 99},
+		{"t,c,w{1000,995,100}", 1000, 995, 100, 100},
+	}
+
+	for _, tc := range cases {
+		got := Percentage(tc.total, tc.current, tc.width)
+		if got != tc.expected {
+			t.Errorf("%s: Expected: %d, got: %d\n", tc.name, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package internal
+
+// Truncate returns s cut to at most width columns on screen, see
+// StringWidth. Cut never happens in the middle of a multi-byte rune,
+// wide rune, which doesn't fit entirely, is dropped. Non positive
+// width yields empty string.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	var n int
+	for i, r := range s {
+		n += RuneWidth(r)
+		if n > width {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,31 @@
+package internal
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"", 5, ""},
+		{"panic", -1, ""},
+		{"panic", 0, ""},
+		{"panic", 3, "pan"},
+		{"panic", 5, "panic"},
+		{"panic", 10, "panic"},
+		{"进度条错误", 2, "进"},
+		{"进度条错误", 5, "进度"},
+		{"进度条错误", 10, "进度条错误"},
+		{"a进b度", 3, "a进"},
+		{"a进b度", 4, "a进b"},
+		{"e\u0301x", 2, "e\u0301x"},
+	}
+
+	for _, test := range tests {
+		got := Truncate(test.s, test.width)
+		if got != test.want {
+			t.Errorf("Truncate(%q, %d) want: %q, got: %q\n", test.s, test.width, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wide are ranges of East Asian wide and fullwidth runes, which take
+// two columns on screen.
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1},
+		{0x2e80, 0x303e, 1},
+		{0x3041, 0x33ff, 1},
+		{0x3400, 0x4dbf, 1},
+		{0x4e00, 0x9fff, 1},
+		{0xa000, 0xa4cf, 1},
+		{0xac00, 0xd7a3, 1},
+		{0xf900, 0xfaff, 1},
+		{0xfe30, 0xfe4f, 1},
+		{0xff00, 0xff60, 1},
+		{0xffe0, 0xffe6, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f300, 0x1f64f, 1},
+		{0x1f900, 0x1f9ff, 1},
+		{0x20000, 0x2fffd, 1},
+		{0x30000, 0x3fffd, 1},
+	},
+}
+
+// RuneWidth returns count of columns r takes on screen: 2 for wide
+// runes, 0 for combining marks and control runes, 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(wide, r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// StringWidth returns count of columns s takes on screen.
+func StringWidth(s string) int {
+	var n int
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// BytesWidth is like StringWidth, but for a byte slice.
+func BytesWidth(b []byte) int {
+	var n int
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		n += RuneWidth(r)
+		b = b[size:]
+	}
+	return n
+}
@@ -0,0 +1,23 @@
+package internal
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tests := map[string]int{
+		"":          0,
+		"panic":     5,
+		"进度条错误":     10,
+		"a进b度":      6,
+		"é":        1,
+		"[===>---]": 9,
+		"ｆｕｌｌ":      8,
+	}
+	for s, want := range tests {
+		if got := StringWidth(s); got != want {
+			t.Errorf("StringWidth(%q) want: %d, got: %d\n", s, want, got)
+		}
+		if got := BytesWidth([]byte(s)); got != want {
+			t.Errorf("BytesWidth(%q) want: %d, got: %d\n", s, want, got)
+		}
+	}
+}
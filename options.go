@@ -2,6 +2,7 @@ package mpb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"sync"
 	"time"
@@ -31,6 +32,67 @@ func WithWidth(w int) ContainerOption {
 	}
 }
 
+// WithTrimSpace trims edge spaces of every bar, see TrimSpace. Bars
+// may opt out with BarTrimSpace(false).
+func WithTrimSpace() ContainerOption {
+	return func(s *pState) {
+		s.trimSpace = true
+	}
+}
+
+// WithMinBarWidth sets minimal width of bar's filler. If decorators
+// leave less space than n, filler is omitted and only decorators are
+// rendered, instead of a few cells of noise. Zero disables the check.
+func WithMinBarWidth(n int) ContainerOption {
+	return func(s *pState) {
+		if n >= 0 {
+			s.minBarWidth = n
+		}
+	}
+}
+
+// WithFallbackWidth sets width, bars are clamped to, when output isn't
+// a terminal or its width can't be detected. Zero means no clamping at
+// all, so bars render at their full width, which suits piped output.
+// Extender fillers then receive very large width, so they must not
+// fill it blindly. Default is container's width, see WithWidth.
+func WithFallbackWidth(w int) ContainerOption {
+	return func(s *pState) {
+		if w >= 0 {
+			s.fallbackWidth = w
+		}
+	}
+}
+
+// WithTerminalWidthFunc replaces terminal width detection with fn,
+// which is called on each refresh. It's a testing aid, which makes
+// width dependent behavior testable without a real terminal. Error
+// returned by fn is handled the same way as with non terminal output,
+// see WithFallbackWidth.
+func WithTerminalWidthFunc(fn func() (int, error)) ContainerOption {
+	return func(s *pState) {
+		s.widthFunc = fn
+	}
+}
+
+// WithClock replaces time source of every bar, see BarClock. Meant
+// for deterministic rendering in tests, production code should leave
+// it default, which is time.Now. Refresh timing is not affected.
+func WithClock(now func() time.Time) ContainerOption {
+	return func(s *pState) {
+		s.clock = now
+	}
+}
+
+// WithDecoratorSeparator sets separator, which is inserted between
+// adjacent decorators of every bar. Separators don't break width
+// synchronization, as all bars in a column share the same separator.
+func WithDecoratorSeparator(sep string) ContainerOption {
+	return func(s *pState) {
+		s.decorSep = sep
+	}
+}
+
 // WithRefreshRate overrides default 120ms refresh rate.
 func WithRefreshRate(d time.Duration) ContainerOption {
 	return func(s *pState) {
@@ -41,6 +103,30 @@ func WithRefreshRate(d time.Duration) ContainerOption {
 	}
 }
 
+// WithMaxFPS limits how many times per second terminal is updated,
+// regardless of refresh rate. Refreshes within a frame window are
+// coalesced into one, which draws the latest state. Useful for slow
+// terminals, like ones over ssh.
+func WithMaxFPS(n int) ContainerOption {
+	return func(s *pState) {
+		if n <= 0 {
+			return
+		}
+		s.frameInterval = time.Second / time.Duration(n)
+	}
+}
+
+// WithRenderDelay suppresses output of bars until ch is closed, so
+// quick operations don't flash bars for a split second. Bars progress
+// and complete as usual meanwhile. If all bars complete before ch is
+// closed, they're never displayed. Lines written to Progress.Output
+// are printed regardless.
+func WithRenderDelay(ch <-chan struct{}) ContainerOption {
+	return func(s *pState) {
+		s.renderDelay = ch
+	}
+}
+
 // WithManualRefresh disables internal auto refresh time.Ticker.
 // Refresh will occur upon receive value from provided ch.
 func WithManualRefresh(ch <-chan time.Time) ContainerOption {
@@ -67,6 +153,17 @@ func WithShutdownNotifier(ch chan struct{}) ContainerOption {
 	}
 }
 
+// WithOnShutdown sets a callback, which is called once, after all bars
+// have completed, to write a summary below them, e.g. "Done: 12 files in
+// 3m12s". Provided writer is positioned right after the final frame.
+// It's called from the render loop, so it must not call methods of the
+// same container.
+func WithOnShutdown(fn func(w io.Writer)) ContainerOption {
+	return func(s *pState) {
+		s.onShutdown = fn
+	}
+}
+
 // WithOutput overrides default output os.Stdout.
 func WithOutput(w io.Writer) ContainerOption {
 	return func(s *pState) {
@@ -77,6 +174,132 @@ func WithOutput(w io.Writer) ContainerOption {
 	}
 }
 
+// WithForceSimpleOutput disables cursor movement, so each frame is
+// appended below the previous one, instead of overwriting it. It's an
+// escape hatch for terminals, which garble multi bar output, e.g. old
+// Windows consoles without ANSI support reached over a pipe. Combine it
+// with a slow refresh rate, to keep output readable.
+func WithForceSimpleOutput() ContainerOption {
+	return func(s *pState) {
+		s.simpleOutput = true
+	}
+}
+
+// WithSkipUnchanged makes container skip writing a frame, which is
+// identical to the previous one, so idle bars cause no terminal
+// traffic. Bars are still rendered on each refresh, so width sync
+// works as usual. As bars region is redrawn as a whole, a frame is
+// written, if any of its lines has changed. Don't combine it with
+// printing to the same terminal by other means than Progress.Output.
+func WithSkipUnchanged() ContainerOption {
+	return func(s *pState) {
+		s.skipUnchanged = true
+	}
+}
+
+// WithJSONOutput switches container to machine readable output.
+// Instead of drawing bars, one JSON object per bar is written to w on
+// each refresh. See BarEvent for the object's fields.
+func WithJSONOutput(w io.Writer) ContainerOption {
+	return func(s *pState) {
+		if w == nil {
+			return
+		}
+		s.jsonEnc = json.NewEncoder(w)
+	}
+}
+
+// WithMetricsHook sets a callback, which is called for each bar on
+// each refresh, e.g. to feed metrics gauges. It's called from the
+// render loop, so it must be short and must not block.
+func WithMetricsHook(fn func(id int, current, total int64)) ContainerOption {
+	return func(s *pState) {
+		s.metricsHook = fn
+	}
+}
+
+// WithEventSink sets a sink, which receives add, complete and abort
+// events of each bar. Sink methods are called from the render loop,
+// so they must be short and must not block.
+func WithEventSink(sink EventSink) ContainerOption {
+	return func(s *pState) {
+		s.eventSink = sink
+	}
+}
+
+// WithOutputs is like WithOutput, but duplicates output to all ws.
+// The first one is primary, terminal width is taken from it. Wrap
+// non terminal writers, like log files, with PlainOutput.
+func WithOutputs(ws ...io.Writer) ContainerOption {
+	return func(s *pState) {
+		var outputs []io.Writer
+		for _, w := range ws {
+			if w != nil {
+				outputs = append(outputs, w)
+			}
+		}
+		if len(outputs) == 0 {
+			return
+		}
+		s.output = outputs[0]
+		s.extraOutputs = outputs[1:]
+	}
+}
+
+// WithErrorHandler sets a callback, which is invoked on every render
+// error, e.g. when output has been closed. It's called from the render
+// loop, so it must not block and must not call methods of the same
+// container. Broken pipe (EPIPE) is reported once, as output is
+// discarded afterwards, while bars keep running till completion.
+func WithErrorHandler(fn func(error)) ContainerOption {
+	return func(s *pState) {
+		s.errorHandler = fn
+	}
+}
+
+// WithTitle sets header line, which is rendered once above all bars.
+// Title may span several lines, separated by "\n". Title can be updated
+// later with Progress.SetTitle.
+func WithTitle(title string) ContainerOption {
+	return func(s *pState) {
+		s.title = title
+	}
+}
+
+// WithLineTerminator sets terminator of each rendered line, "\n" by
+// default. Use "\r\n" for consoles, which don't return carriage on line
+// feed.
+func WithLineTerminator(term string) ContainerOption {
+	return func(s *pState) {
+		if term == "" {
+			return
+		}
+		s.lineTerm = term
+	}
+}
+
+// WithFrameHistory keeps last n rendered frames, including control
+// sequences, which are accessible via Progress.LastFrames. Meant for
+// diagnostics of rendering issues. Zero n disables history.
+func WithFrameHistory(n int) ContainerOption {
+	return func(s *pState) {
+		if n <= 0 {
+			s.history = nil
+			return
+		}
+		s.history = &frameRing{buf: make([]string, n)}
+	}
+}
+
+// WithPipeDelimiter sets delimiter, which is written after each frame
+// streamed by Progress.Pipe, "\n" by default. Empty delim means frames
+// follow each other as is.
+func WithPipeDelimiter(delim string) ContainerOption {
+	return func(s *pState) {
+		s.pipeDelim = delim
+	}
+}
+
 // WithDebugOutput sets debug output.
 func WithDebugOutput(w io.Writer) ContainerOption {
 	return func(s *pState) {
@@ -87,6 +310,41 @@ func WithDebugOutput(w io.Writer) ContainerOption {
 	}
 }
 
+// WithSortBars enables reordering of bars on each refresh, according
+// to provided less func. Bars, which are equal according to less, are
+// ordered by priority, see BarPriority and UpdateBarPriority. See
+// SortIncompleteFirst and SortMostProgressedFirst for built-in
+// comparators.
+func WithSortBars(less func(a, b *Bar) bool) ContainerOption {
+	return func(s *pState) {
+		s.sortLess = less
+	}
+}
+
+// WithBarComparator replaces priority based order of bars with less
+// func, which has full control over ordering and tie-breaking. Bars
+// are ordered by it on each refresh, as well as when added. It takes
+// precedence over WithSortBars and priorities. It's called from the
+// render loop, so it must be short and must not call methods of the
+// container.
+func WithBarComparator(less func(a, b *Bar) bool) ContainerOption {
+	return func(s *pState) {
+		s.bHeap.less = less
+	}
+}
+
+// SortIncompleteFirst is a comparator for WithSortBars, which floats
+// incomplete bars to the top.
+func SortIncompleteFirst(a, b *Bar) bool {
+	return !a.lastEvent.done() && b.lastEvent.done()
+}
+
+// SortMostProgressedFirst is a comparator for WithSortBars, which
+// orders bars by percentage of progress, the most progressed on top.
+func SortMostProgressedFirst(a, b *Bar) bool {
+	return a.lastEvent.percent() > b.lastEvent.percent()
+}
+
 // ContainerOptOnCond returns option when condition evaluates to true.
 func ContainerOptOnCond(option ContainerOption, condition func() bool) ContainerOption {
 	if condition() {
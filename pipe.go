@@ -0,0 +1,59 @@
+package mpb
+
+import "io"
+
+// framePipe feeds rendered frames into a pipe, see Progress.Pipe. It
+// keeps the latest frame only, so slow reader never blocks rendering,
+// it just skips frames.
+type framePipe struct {
+	pw     *io.PipeWriter
+	frames chan []byte
+	// closed is closed, once reader has gone
+	closed chan struct{}
+}
+
+func newFramePipe() (*framePipe, io.Reader) {
+	pr, pw := io.Pipe()
+	fp := &framePipe{
+		pw:     pw,
+		frames: make(chan []byte, 1),
+		closed: make(chan struct{}),
+	}
+	go fp.serve()
+	return fp, pr
+}
+
+func (fp *framePipe) serve() {
+	defer close(fp.closed)
+	for frame := range fp.frames {
+		if _, err := fp.pw.Write(frame); err != nil {
+			return
+		}
+	}
+	fp.pw.Close()
+}
+
+// push queues frame, replacing pending one, if any. It reports false,
+// if reader has gone. Must not be called concurrently.
+func (fp *framePipe) push(frame []byte) bool {
+	select {
+	case <-fp.closed:
+		return false
+	default:
+	}
+	select {
+	case fp.frames <- frame:
+	default:
+		select {
+		case <-fp.frames:
+		default:
+		}
+		fp.frames <- frame
+	}
+	return true
+}
+
+// close makes reader receive io.EOF, after pending frame is read.
+func (fp *framePipe) close() {
+	close(fp.frames)
+}
@@ -0,0 +1,39 @@
+package mpb
+
+import "io"
+
+// PlainOutput wraps w, so ANSI escape sequences and carriage returns
+// are stripped from everything written to it. Each rendered frame ends
+// up as plain text lines, which suits log files.
+func PlainOutput(w io.Writer) io.Writer {
+	return &plainWriter{w: w}
+}
+
+type plainWriter struct {
+	w     io.Writer
+	buf   []byte
+	inEsc bool
+	inCSI bool
+}
+
+func (pw *plainWriter) Write(p []byte) (int, error) {
+	pw.buf = pw.buf[:0]
+	for _, c := range p {
+		switch {
+		case pw.inCSI:
+			// CSI sequence ends with a byte in 0x40-0x7e range
+			pw.inCSI = c < 0x40 || c > 0x7e
+		case pw.inEsc:
+			pw.inEsc = false
+			pw.inCSI = c == '['
+		case c == 0x1b:
+			pw.inEsc = true
+		case c != '\r':
+			pw.buf = append(pw.buf, c)
+		}
+	}
+	if _, err := pw.w.Write(pw.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
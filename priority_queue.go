@@ -1,40 +1,105 @@
 package mpb
 
-import "container/heap"
+import (
+	"container/heap"
+	"sort"
+)
 
-// A priorityQueue implements heap.Interface
-type priorityQueue []*Bar
+// A priorityQueue implements heap.Interface. Bars are ordered by
+// priority, unless less func is set, see WithBarComparator, or they
+// have been ranked by sortBy.
+type priorityQueue struct {
+	bars   []*Bar
+	less   func(a, b *Bar) bool
+	ranked bool
+}
+
+func (pq *priorityQueue) Len() int { return len(pq.bars) }
 
-func (pq priorityQueue) Len() int { return len(pq) }
+func (pq *priorityQueue) Less(i, j int) bool {
+	return pq.before(pq.bars[i], pq.bars[j])
+}
 
-func (pq priorityQueue) Less(i, j int) bool {
-	return pq[i].priority < pq[j].priority
+func (pq *priorityQueue) before(a, b *Bar) bool {
+	switch {
+	case pq.less != nil:
+		return pq.less(a, b)
+	case pq.ranked:
+		return a.rank < b.rank
+	default:
+		return a.priority < b.priority
+	}
 }
 
-func (pq priorityQueue) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
-	pq[i].index = i
-	pq[j].index = j
+func (pq *priorityQueue) Swap(i, j int) {
+	pq.bars[i], pq.bars[j] = pq.bars[j], pq.bars[i]
+	pq.bars[i].index = i
+	pq.bars[j].index = j
 }
 
 func (pq *priorityQueue) Push(x interface{}) {
-	n := len(*pq)
+	n := len(pq.bars)
 	bar := x.(*Bar)
 	bar.index = n
-	*pq = append(*pq, bar)
+	pq.bars = append(pq.bars, bar)
 }
 
 func (pq *priorityQueue) Pop() interface{} {
-	old := *pq
+	old := pq.bars
 	n := len(old)
 	bar := old[n-1]
 	bar.index = -1 // for safety
-	*pq = old[0 : n-1]
+	pq.bars = old[0 : n-1]
 	return bar
 }
 
+// contains reports whether bar is in the queue, bar's index is
+// trusted only if it points back to the bar itself.
+func (pq *priorityQueue) contains(bar *Bar) bool {
+	return bar.index >= 0 && bar.index < len(pq.bars) && pq.bars[bar.index] == bar
+}
+
 // update modifies the priority of a Bar in the queue.
 func (pq *priorityQueue) update(bar *Bar, priority int) {
+	if bar.pinSeq != 0 {
+		// pinned bar stays on top, priority takes effect on Unpin
+		bar.unpinnedPriority = priority
+		return
+	}
 	bar.priority = priority
-	heap.Fix(pq, bar.index)
+	if pq.contains(bar) {
+		heap.Fix(pq, bar.index)
+	}
+}
+
+// sortBy reorders the queue according to less func, ties are broken by
+// priority. Bars are ranked to reflect the new order, so heap invariant
+// holds, while priorities are kept intact.
+func (pq *priorityQueue) sortBy(less func(a, b *Bar) bool) {
+	bars := make([]*Bar, len(pq.bars))
+	copy(bars, pq.bars)
+	sort.SliceStable(bars, func(i, j int) bool {
+		if less(bars[i], bars[j]) {
+			return true
+		}
+		if less(bars[j], bars[i]) {
+			return false
+		}
+		return bars[i].priority < bars[j].priority
+	})
+	for i, bar := range bars {
+		bar.rank = i
+	}
+	pq.ranked = true
+	heap.Init(pq)
+}
+
+// sorted returns copy of the queue, in order bars are rendered.
+func (pq *priorityQueue) sorted() []*Bar {
+	bars := make([]*Bar, len(pq.bars))
+	copy(bars, pq.bars)
+	sort.SliceStable(bars, func(i, j int) bool {
+		return pq.before(bars[i], bars[j])
+	})
+	return bars
 }
@@ -1,16 +1,24 @@
 package mpb
 
 import (
+	"bytes"
 	"container/heap"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/vbauerster/mpb/v4/cwriter"
+	"github.com/vbauerster/mpb/v4/decor"
+	"github.com/vbauerster/mpb/v4/internal"
 )
 
 const (
@@ -18,8 +26,14 @@ const (
 	prr = 120 * time.Millisecond
 	// default width
 	pwidth = 80
+	// termWidth, which never clamps a bar, see WithFallbackWidth
+	unlimitedWidth = math.MaxInt32
 )
 
+// ErrShutdown is returned by methods, which can't be served after
+// container has been shut down.
+var ErrShutdown = errors.New("mpb: container has been shut down")
+
 // Progress represents the container that renders Progress bars
 type Progress struct {
 	uwg          *sync.WaitGroup
@@ -35,17 +49,51 @@ type pState struct {
 	heapUpdated     bool
 	idCounter       int
 	width           int
+	lastTermWidth   int
+	hidden          bool
+	visibilityAck   chan struct{}
 	rr              time.Duration
+	frameInterval   time.Duration
+	lastFlush       time.Time
 	pMatrix         map[int][]chan int
 	aMatrix         map[int][]chan int
 	forceRefreshCh  chan time.Time
 	output          io.Writer
+	extraOutputs    []io.Writer
+	jsonEnc         *json.Encoder
+	sortLess        func(a, b *Bar) bool
+	decorSep        string
+	metricsHook     func(id int, current, total int64)
+	eventSink       EventSink
+	clock           func() time.Time
+	errorHandler    func(error)
+	title           string
+	lineTerm        string
+	history         *frameRing
+	pendingOut      bytes.Buffer
+	pipes           []*framePipe
+	pipeDelim       string
+	minBarWidth     int
+	trimSpace       bool
+	// closing is set by Wait, no bar may be added afterwards
+	closing bool
+	// negative fallbackWidth means container's width
+	fallbackWidth int
+	// finalFlush is set for the last flush before quit
+	finalFlush bool
+	// simpleOutput disables cursor control, see WithForceSimpleOutput
+	simpleOutput bool
+	// skipUnchanged suppresses identical frames, see WithSkipUnchanged
+	skipUnchanged bool
 
 	// following are provided/overrided by user
 	ctx              context.Context
 	uwg              *sync.WaitGroup
 	manualRefreshCh  <-chan time.Time
 	shutdownNotifier chan struct{}
+	onShutdown       func(io.Writer)
+	renderDelay      <-chan struct{}
+	widthFunc        func() (int, error)
 	waitBars         map[*Bar]*Bar
 	debugOut         io.Writer
 }
@@ -53,18 +101,18 @@ type pState struct {
 // New creates new Progress instance, which orchestrates bars rendering
 // process. Accepts mpb.ContainerOption funcs for customization.
 func New(options ...ContainerOption) *Progress {
-	pq := make(priorityQueue, 0)
-	heap.Init(&pq)
-
 	s := &pState{
 		ctx:            context.Background(),
-		bHeap:          &pq,
+		bHeap:          new(priorityQueue),
 		width:          pwidth,
 		rr:             prr,
 		waitBars:       make(map[*Bar]*Bar),
 		debugOut:       ioutil.Discard,
 		forceRefreshCh: make(chan time.Time),
 		output:         os.Stdout,
+		lineTerm:       "\n",
+		pipeDelim:      "\n",
+		fallbackWidth:  -1,
 	}
 
 	for _, opt := range options {
@@ -80,8 +128,15 @@ func New(options ...ContainerOption) *Progress {
 		operateState: make(chan func(*pState)),
 		done:         make(chan struct{}),
 	}
+	cw := cwriter.New(s.output, s.extraOutputs...)
+	if s.simpleOutput {
+		cw.DisableCursorControl()
+	}
+	if s.skipUnchanged {
+		cw.SkipUnchanged()
+	}
 	p.cwg.Add(1)
-	go p.serve(s, cwriter.New(s.output))
+	go p.serve(s, cw)
 	return p
 }
 
@@ -90,6 +145,14 @@ func (p *Progress) AddBar(total int64, options ...BarOption) *Bar {
 	return p.Add(total, newDefaultBarFiller(), options...)
 }
 
+// AddPercentageBar creates a new progress bar with percentage
+// decorator appended, and adds it to the container. Provided options
+// are applied after the preset ones.
+func (p *Progress) AddPercentageBar(total int64, options ...BarOption) *Bar {
+	options = append([]BarOption{AppendDecorators(decor.Percentage(decor.WC{W: 5}))}, options...)
+	return p.AddBar(total, options...)
+}
+
 // AddSpinner creates a new spinner bar and adds to the container.
 func (p *Progress) AddSpinner(total int64, alignment SpinnerAlignment, options ...BarOption) *Bar {
 	filler := &spinnerFiller{
@@ -99,41 +162,106 @@ func (p *Progress) AddSpinner(total int64, alignment SpinnerAlignment, options .
 	return p.Add(total, filler, options...)
 }
 
+// AddIndeterminate creates a new bar with a bouncing block, suitable
+// when progress can't be measured, and adds it to the container.
+func (p *Progress) AddIndeterminate(total int64, options ...BarOption) *Bar {
+	return p.Add(total, newBounceFiller(), options...)
+}
+
 // Add creates a bar which renders itself by provided filler.
+// If container has been shut down already, i.e. Wait has returned,
+// a dead bar is returned instead of nil. Dead bar is never rendered
+// and all of its methods are safe no-ops, so there is no need to nil
+// check the result, like in `p.AddBar(total).ProxyReader(r)`.
 func (p *Progress) Add(total int64, filler Filler, options ...BarOption) *Bar {
 	p.bwg.Add(1)
 	result := make(chan *Bar)
 	select {
 	case p.operateState <- func(s *pState) {
-		b := newBar(s.ctx, p.bwg, filler, s.idCounter, s.width, total, options...)
-		if b.runningBar != nil {
-			s.waitBars[b.runningBar] = b
-		} else {
-			heap.Push(s.bHeap, b)
-			s.heapUpdated = true
-		}
-		s.idCounter++
-		result <- b
+		result <- s.addBar(p.bwg, total, filler, options)
 	}:
 		return <-result
 	case <-p.done:
 		p.bwg.Done()
-		return nil
+		return newDeadBar(filler, total)
+	}
+}
+
+// BarSpec describes a bar for AddBars. Nil Filler means default bar
+// filler, like one of AddBar.
+type BarSpec struct {
+	Total   int64
+	Filler  Filler
+	Options []BarOption
+}
+
+func (spec BarSpec) filler() Filler {
+	if spec.Filler == nil {
+		return newDefaultBarFiller()
+	}
+	return spec.Filler
+}
+
+// AddBars creates bars according to provided specs and adds them to the
+// container at once, which is cheaper than calling Add for each spec.
+// Bars are returned in order of specs. Like with Add, dead bars are
+// returned if container has been shut down already.
+func (p *Progress) AddBars(specs []BarSpec) []*Bar {
+	p.bwg.Add(len(specs))
+	result := make(chan []*Bar)
+	select {
+	case p.operateState <- func(s *pState) {
+		bars := make([]*Bar, len(specs))
+		for i, spec := range specs {
+			bars[i] = s.addBar(p.bwg, spec.Total, spec.filler(), spec.Options)
+		}
+		result <- bars
+	}:
+		return <-result
+	case <-p.done:
+		bars := make([]*Bar, len(specs))
+		for i, spec := range specs {
+			p.bwg.Done()
+			bars[i] = newDeadBar(spec.filler(), spec.Total)
+		}
+		return bars
 	}
 }
 
 // Abort is only effective while bar progress is running, it means
 // remove bar now without waiting for its completion. If bar is already
 // completed, there is nothing to abort. If you need to remove bar
-// after completion, use BarRemoveOnComplete BarOption.
+// after completion, use BarRemoveOnComplete BarOption. Bar which is
+// waiting for its BarReplaceOnComplete turn is dropped, as it has
+// never been rendered. It's safe to call Abort more than once.
+// Aborted bar, which isn't removed, displays BarAbortMessage, if set.
 func (p *Progress) Abort(b *Bar, remove bool) {
+	if !remove {
+		b.markAborted()
+	}
 	select {
 	case p.operateState <- func(s *pState) {
-		if b.index < 0 {
+		for runningBar, waitingBar := range s.waitBars {
+			if waitingBar == b {
+				delete(s.waitBars, runningBar)
+				b.removed()
+				s.shutdownPending = append(s.shutdownPending, b)
+				s.notifyDone(b, true)
+				return
+			}
+		}
+		if !s.bHeap.contains(b) {
 			return
 		}
+		s.notifyDone(b, true)
+		if replacementBar, ok := s.waitBars[b]; ok {
+			heap.Push(s.bHeap, replacementBar)
+			s.heapUpdated = true
+			delete(s.waitBars, b)
+		}
 		if remove {
 			s.heapUpdated = heap.Remove(s.bHeap, b.index) != nil
+			b.removed()
 		}
 		s.shutdownPending = append(s.shutdownPending, b)
 	}:
@@ -141,8 +269,40 @@ func (p *Progress) Abort(b *Bar, remove bool) {
 	}
 }
 
+// AbortGroup aborts all bars tagged with BarGroup(name), including
+// ones waiting for their BarReplaceOnComplete turn, see Abort.
+func (p *Progress) AbortGroup(name string, remove bool) {
+	result := make(chan []*Bar, 1)
+	select {
+	case p.operateState <- func(s *pState) {
+		var bars []*Bar
+		for _, b := range s.bHeap.bars {
+			if b.group == name {
+				bars = append(bars, b)
+			}
+		}
+		for _, b := range s.waitBars {
+			if b.group == name {
+				bars = append(bars, b)
+			}
+		}
+		result <- bars
+	}:
+	case <-p.done:
+		return
+	}
+	// Abort goes through operateState as well, so it's called outside
+	for _, b := range <-result {
+		p.Abort(b, remove)
+	}
+}
+
 // UpdateBarPriority provides a way to change bar's order position.
-// Zero is highest priority, i.e. bar will be on top.
+// Zero is highest priority, i.e. bar will be on top. It's safe to call
+// at any time: priority of a bar, which isn't rendered at the moment,
+// e.g. one waiting for BarReplaceOnComplete turn, is kept and takes
+// effect once the bar is rendered. Priority of a pinned bar takes
+// effect on Unpin, see Bar.Pin.
 func (p *Progress) UpdateBarPriority(b *Bar, priority int) {
 	select {
 	case p.operateState <- func(s *pState) { s.bHeap.update(b, priority) }:
@@ -150,6 +310,135 @@ func (p *Progress) UpdateBarPriority(b *Bar, priority int) {
 	}
 }
 
+// BarByID returns bar with provided id, or nil if there is no such
+// bar. Bars waiting for their BarReplaceOnComplete turn are looked up
+// as well.
+func (p *Progress) BarByID(id int) *Bar {
+	result := make(chan *Bar, 1)
+	select {
+	case p.operateState <- func(s *pState) {
+		for _, b := range s.bHeap.bars {
+			if b.id == id {
+				result <- b
+				return
+			}
+		}
+		for _, b := range s.waitBars {
+			if b.id == id {
+				result <- b
+				return
+			}
+		}
+		result <- nil
+	}:
+		return <-result
+	case <-p.done:
+		return nil
+	}
+}
+
+// SetTitle updates header line set by WithTitle, empty title removes
+// header line.
+func (p *Progress) SetTitle(title string) {
+	select {
+	case p.operateState <- func(s *pState) { s.title = title }:
+	case <-p.done:
+	}
+}
+
+// RenderFrame returns current frame of all bars, like the one written
+// on refresh, but without terminal control sequences. It doesn't
+// affect live rendering, so it's suitable for embedding bars into
+// another UI. ErrShutdown is returned after container has quit.
+func (p *Progress) RenderFrame() ([]byte, error) {
+	type result struct {
+		frame []byte
+		err   error
+	}
+	ch := make(chan result, 1)
+	select {
+	case p.operateState <- func(s *pState) {
+		var buf bytes.Buffer
+		err := s.renderFrame(&buf)
+		ch <- result{buf.Bytes(), err}
+	}:
+		r := <-ch
+		return r.frame, r.err
+	case <-p.done:
+		return nil, ErrShutdown
+	}
+}
+
+// ForEachBar calls fn for each bar in the container, from top to
+// bottom, while no bar can be added or removed. fn is called from the
+// container's goroutine, so it must not call methods of the container
+// itself, or it deadlocks. Methods of provided bar are fine to call.
+func (p *Progress) ForEachBar(fn func(*Bar)) {
+	done := make(chan struct{})
+	select {
+	case p.operateState <- func(s *pState) {
+		defer close(done)
+		for _, b := range s.bHeap.sorted() {
+			fn(b)
+		}
+	}:
+		<-done
+	case <-p.done:
+	}
+}
+
+// LastFrames returns frames kept by WithFrameHistory, oldest first.
+// Nil is returned if history is disabled or container has quit.
+func (p *Progress) LastFrames() []string {
+	result := make(chan []string, 1)
+	select {
+	case p.operateState <- func(s *pState) { result <- s.history.frames() }:
+		return <-result
+	case <-p.done:
+		return nil
+	}
+}
+
+// Output returns writer, which prints above bars, without corrupting
+// them. Written data is queued and printed on next refresh, complete
+// lines only. Writing after container has quit results in ErrShutdown.
+func (p *Progress) Output() io.Writer {
+	return (*progressWriter)(p)
+}
+
+type progressWriter Progress
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	// b must not be retained, so copy is made before handing it over
+	data := append([]byte(nil), b...)
+	select {
+	case w.operateState <- func(s *pState) { s.pendingOut.Write(data) }:
+		return len(b), nil
+	case <-w.done:
+		return 0, ErrShutdown
+	}
+}
+
+// Pipe returns reader, which streams rendered frames, including
+// terminal control sequences, as they're written to output. Frames are
+// separated by delimiter set with WithPipeDelimiter. Slow reader skips
+// frames, but never slows rendering down, and the latest frame is
+// always delivered. Closing reader, if it's an io.Closer, stops the
+// stream. Reader gets io.EOF once container has quit.
+func (p *Progress) Pipe() io.Reader {
+	result := make(chan io.Reader, 1)
+	select {
+	case p.operateState <- func(s *pState) {
+		fp, r := newFramePipe()
+		s.pipes = append(s.pipes, fp)
+		result <- r
+	}:
+		return <-result
+	case <-p.done:
+		return bytes.NewReader(nil)
+	}
+}
+
 // BarCount returns bars count
 func (p *Progress) BarCount() int {
 	result := make(chan int, 1)
@@ -161,6 +450,64 @@ func (p *Progress) BarCount() int {
 	}
 }
 
+// RefreshRate returns configured refresh rate, zero is returned after
+// container has quit.
+func (p *Progress) RefreshRate() time.Duration {
+	result := make(chan time.Duration, 1)
+	select {
+	case p.operateState <- func(s *pState) { result <- s.rr }:
+		return <-result
+	case <-p.done:
+		return 0
+	}
+}
+
+// Width returns configured width of bars, zero is returned after
+// container has quit.
+func (p *Progress) Width() int {
+	result := make(chan int, 1)
+	select {
+	case p.operateState <- func(s *pState) { result <- s.width }:
+		return <-result
+	case <-p.done:
+		return 0
+	}
+}
+
+// Hide clears bars from the terminal and suspends rendering, until
+// Show is called. It blocks until bars region is cleared, so it's safe
+// to hand the terminal over to another process right after. Bars keep
+// counting progress and may complete while hidden, so Wait returns
+// without Show, leaving nothing on the terminal.
+func (p *Progress) Hide() {
+	p.setHidden(true)
+}
+
+// Show resumes rendering suspended by Hide and redraws bars.
+func (p *Progress) Show() {
+	p.setHidden(false)
+}
+
+func (p *Progress) setHidden(hidden bool) {
+	ack := make(chan struct{})
+	select {
+	case p.operateState <- func(s *pState) {
+		s.hidden = hidden
+		s.visibilityAck = ack
+	}:
+		<-ack
+	case <-p.done:
+	}
+}
+
+// Done returns channel, which is closed by Wait, once all bars have
+// quit. Final frame may still be being rendered at that point, Wait
+// returns after it's done. Running Wait in a goroutine and selecting
+// on Done composes container's shutdown with other events.
+func (p *Progress) Done() <-chan struct{} {
+	return p.done
+}
+
 // Wait waits far all bars to complete and finally shutdowns container.
 // After this method has been called, there is no way to reuse *Progress
 // instance.
@@ -170,8 +517,14 @@ func (p *Progress) Wait() {
 		p.uwg.Wait()
 	}
 
-	// wait for bars to quit, if any
-	p.bwg.Wait()
+	// wait for bars to quit, if any. bar may be added right after
+	// bwg.Wait has returned, then wait for it as well.
+	for {
+		p.bwg.Wait()
+		if p.markClosing() {
+			break
+		}
+	}
 
 	close(p.done)
 
@@ -179,6 +532,25 @@ func (p *Progress) Wait() {
 	p.cwg.Wait()
 }
 
+// markClosing forbids adding new bars, if all bars have quit already.
+// It reports whether it has done so.
+func (p *Progress) markClosing() bool {
+	result := make(chan bool, 1)
+	p.operateState <- func(s *pState) {
+		for _, b := range s.bHeap.bars {
+			select {
+			case <-b.done:
+			default:
+				result <- false
+				return
+			}
+		}
+		s.closing = len(s.waitBars) == 0
+		result <- s.closing
+	}
+	return <-result
+}
+
 func (p *Progress) serve(s *pState, cw *cwriter.Writer) {
 	defer p.cwg.Done()
 
@@ -187,51 +559,237 @@ func (p *Progress) serve(s *pState, cw *cwriter.Writer) {
 
 	refreshCh := fanInRefreshSrc(p.done, s.forceRefreshCh, manualOrTickCh)
 
+	// delayedCh fires once, if refresh has been dropped due to max fps
+	var delayedCh <-chan time.Time
+
+	handleError := func(err error) {
+		s.handleError(err)
+		if isBrokenPipe(err) {
+			// reader of output has gone, bars keep running till completion,
+			// but there is no point to write to it anymore
+			cw.DiscardOut()
+			if s.jsonEnc != nil {
+				s.jsonEnc = json.NewEncoder(ioutil.Discard)
+			}
+		}
+	}
+
 	for {
 		select {
 		case op := <-p.operateState:
 			op(s)
+			if s.visibilityAck != nil {
+				var err error
+				if s.hidden {
+					// empty flush clears previously rendered lines
+					err = cw.Flush(0)
+				} else {
+					err = s.render(cw)
+				}
+				if err != nil {
+					handleError(err)
+				}
+				close(s.visibilityAck)
+				s.visibilityAck = nil
+			}
 		case _, ok := <-refreshCh:
 			if !ok {
+				// all bars have quit by now, render their final state
+				// once more, so nothing less than complete is left on screen
+				s.finalFlush = true
+				if err := s.render(cw); err != nil {
+					handleError(err)
+				}
+				for _, fp := range s.pipes {
+					fp.close()
+				}
 				if s.shutdownNotifier != nil {
 					close(s.shutdownNotifier)
 				}
 				return
 			}
+			if wait := s.frameInterval - time.Since(s.lastFlush); wait > 0 {
+				if delayedCh == nil {
+					delayedCh = time.After(wait)
+				}
+				continue
+			}
+			if err := s.render(cw); err != nil {
+				handleError(err)
+			}
+		case <-delayedCh:
+			delayedCh = nil
 			if err := s.render(cw); err != nil {
-				fmt.Fprintf(s.debugOut, "[mpb] %s %v\n", time.Now(), err)
+				handleError(err)
 			}
 		}
 	}
 }
 
+func (s *pState) addBar(wg *sync.WaitGroup, total int64, filler Filler, options []BarOption) *Bar {
+	if s.closing {
+		wg.Done()
+		return newDeadBar(filler, total)
+	}
+	if s.decorSep != "" {
+		options = append([]BarOption{BarDecoratorSeparator(s.decorSep)}, options...)
+	}
+	if s.trimSpace {
+		options = append([]BarOption{TrimSpace()}, options...)
+	}
+	if s.clock != nil {
+		options = append([]BarOption{BarClock(s.clock)}, options...)
+	}
+	options = append(options, func(bs *bState) {
+		bs.forceRefreshCh = s.forceRefreshCh
+		bs.lineTerm = s.lineTerm
+		bs.minWidth = s.minBarWidth
+	})
+	b := newBar(s.ctx, wg, filler, s.idCounter, s.width, total, options...)
+	if b.runningBar != nil {
+		s.waitBars[b.runningBar] = b
+	} else {
+		heap.Push(s.bHeap, b)
+		s.heapUpdated = true
+	}
+	s.idCounter++
+	if s.eventSink != nil {
+		s.eventSink.OnAdd(b.id)
+	}
+	return b
+}
+
+// notifyDone emits either OnComplete or OnAbort to the event sink, but
+// only once per bar.
+func (s *pState) notifyDone(b *Bar, aborted bool) {
+	if s.eventSink == nil || b.sinkNotified {
+		return
+	}
+	b.sinkNotified = true
+	if aborted {
+		s.eventSink.OnAbort(b.id)
+	} else {
+		s.eventSink.OnComplete(b.id)
+	}
+}
+
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
+
+func (s *pState) handleError(err error) {
+	fmt.Fprintf(s.debugOut, "[mpb] %s %v\n", time.Now(), err)
+	if s.errorHandler != nil {
+		s.errorHandler(err)
+	}
+}
+
 func (s *pState) render(cw *cwriter.Writer) error {
 	if s.heapUpdated {
 		s.updateSyncMatrix()
 		s.heapUpdated = false
 	}
+	switch {
+	case s.bHeap.less != nil:
+		// comparator may depend on state, which has changed since last
+		// refresh
+		heap.Init(s.bHeap)
+	case s.sortLess != nil:
+		s.bHeap.sortBy(s.sortLess)
+	}
 	syncWidth(s.pMatrix)
 	syncWidth(s.aMatrix)
 
-	tw, err := cw.GetWidth()
+	getWidth := cw.GetWidth
+	if s.widthFunc != nil {
+		getWidth = s.widthFunc
+	}
+	tw, err := getWidth()
 	if err != nil {
-		tw = s.width
+		switch {
+		case s.fallbackWidth == 0:
+			tw = unlimitedWidth
+		case s.fallbackWidth > 0:
+			tw = s.fallbackWidth
+		default:
+			tw = s.width
+		}
+	}
+	if tw < s.lastTermWidth && !s.hidden {
+		// terminal has been shrunk, previous frame may have been wrapped
+		cw.Reflow(tw)
 	}
+	s.lastTermWidth = tw
 	for i := 0; i < s.bHeap.Len(); i++ {
-		bar := (*s.bHeap)[i]
-		go bar.render(s.debugOut, tw)
+		bar := s.bHeap.bars[i]
+		go bar.render(s.debugOut, tw, false)
 	}
 
 	return s.flush(cw)
 }
 
+// renderFrame writes snapshot of all bars to w, leaving heap and
+// bars' shutdown state intact.
+func (s *pState) renderFrame(w io.Writer) error {
+	if s.heapUpdated {
+		s.updateSyncMatrix()
+		s.heapUpdated = false
+	}
+	syncWidth(s.pMatrix)
+	syncWidth(s.aMatrix)
+
+	tw := s.lastTermWidth
+	if tw <= 0 {
+		tw = s.width
+	}
+	bars := s.bHeap.sorted()
+	for _, bar := range bars {
+		go bar.render(s.debugOut, tw, true)
+	}
+
+	if s.title != "" {
+		s.writeTitle(w, tw)
+	}
+	var err error
+	for _, bar := range bars {
+		frame := <-bar.bFrameCh
+		if frame.syncPending {
+			s.heapUpdated = true
+		}
+		if _, e := io.Copy(w, frame.rd); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
 func (s *pState) flush(cw *cwriter.Writer) error {
 	var lineCount int
+	// first encode error, reported only after flush is complete
+	var encErr error
+	// frames are drained while hidden as well, so bars still shutdown
+	delayed := s.renderDelayed() || s.hidden
+	if i := bytes.LastIndexByte(s.pendingOut.Bytes(), '\n'); i >= 0 && !s.hidden {
+		// complete lines only, not counted, so they stay above bars
+		cw.WriteAbove(s.pendingOut.Next(i + 1))
+	}
+	if s.title != "" && s.jsonEnc == nil && !delayed {
+		lineCount += s.writeTitle(cw, s.lastTermWidth)
+	}
 	for s.bHeap.Len() > 0 {
 		bar := heap.Pop(s.bHeap).(*Bar)
 		frame := <-bar.bFrameCh
+		bar.lastEvent = frame.event
+		bar.applyPin(frame.pinSeq)
+		if frame.syncPending {
+			s.heapUpdated = true
+		}
+		if s.metricsHook != nil {
+			s.metricsHook(frame.event.ID, frame.event.Current, frame.event.Total)
+		}
 		defer func() {
 			if frame.toShutdown {
+				s.notifyDone(bar, false)
 				go func() {
 					// force next refresh, so it will be triggered either by ticker or by
 					// this goroutine, whichever comes first
@@ -251,21 +809,98 @@ func (s *pState) flush(cw *cwriter.Writer) error {
 				}
 				if frame.removeOnComplete {
 					s.heapUpdated = true
+					bar.removed()
 					return
 				}
 			}
 			heap.Push(s.bHeap, bar)
 		}()
+		if delayed {
+			// frame is drained, so bar's buffers are reset
+			io.Copy(ioutil.Discard, frame.rd)
+			continue
+		}
+		if s.jsonEnc != nil {
+			// drawn frame isn't used, still it's drained to reset bar's buffers
+			io.Copy(ioutil.Discard, frame.rd)
+			if err := s.jsonEnc.Encode(frame.event); err != nil && encErr == nil {
+				encErr = err
+			}
+			continue
+		}
 		cw.ReadFrom(frame.rd)
 		lineCount += frame.extendedLines + 1
 	}
 
 	for i := len(s.shutdownPending) - 1; i >= 0; i-- {
-		close(s.shutdownPending[i].shutdown)
+		bar := s.shutdownPending[i]
+		select {
+		case <-bar.shutdown:
+			// aborted bar may get here once more, by completing
+		default:
+			close(bar.shutdown)
+		}
 		s.shutdownPending = s.shutdownPending[:i]
 	}
 
-	return cw.Flush(lineCount)
+	if s.hidden {
+		// terminal belongs to someone else, nothing is written to it
+		return encErr
+	}
+	if s.finalFlush && s.onShutdown != nil {
+		// below bars and not counted, as nothing is redrawn afterwards
+		s.onShutdown(cw)
+	}
+	s.lastFlush = time.Now()
+	if s.history != nil {
+		s.history.push(string(cw.Buffered()))
+	}
+	if len(s.pipes) != 0 {
+		s.pushToPipes(cw.Buffered())
+	}
+	if err := cw.Flush(lineCount); err != nil {
+		return err
+	}
+	return encErr
+}
+
+// writeTitle writes title line by line, each one truncated to width,
+// so it never wraps. It returns count of lines written.
+func (s *pState) writeTitle(w io.Writer, width int) int {
+	lines := strings.Split(s.title, "\n")
+	for _, line := range lines {
+		io.WriteString(w, internal.Truncate(line, width)+s.lineTerm)
+	}
+	return len(lines)
+}
+
+// pushToPipes tees frame into each pipe, dropping ones whose reader
+// has gone.
+func (s *pState) pushToPipes(frame []byte) {
+	data := make([]byte, 0, len(frame)+len(s.pipeDelim))
+	data = append(append(data, frame...), s.pipeDelim...)
+	pipes := s.pipes[:0]
+	for _, fp := range s.pipes {
+		if fp.push(data) {
+			pipes = append(pipes, fp)
+		}
+	}
+	s.pipes = pipes
+}
+
+// renderDelayed reports whether output is still suppressed by
+// WithRenderDelay.
+func (s *pState) renderDelayed() bool {
+	if s.renderDelay == nil {
+		return false
+	}
+	select {
+	case <-s.renderDelay:
+		s.renderDelay = nil
+		return false
+	default:
+		return true
+	}
 }
 
 func (s *pState) manualOrTick() (<-chan time.Time, func()) {
@@ -280,7 +915,7 @@ func (s *pState) updateSyncMatrix() {
 	s.pMatrix = make(map[int][]chan int)
 	s.aMatrix = make(map[int][]chan int)
 	for i := 0; i < s.bHeap.Len(); i++ {
-		bar := (*s.bHeap)[i]
+		bar := s.bHeap.bars[i]
 		table := bar.wSyncTable()
 		pRow, aRow := table[0], table[1]
 
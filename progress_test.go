@@ -3,13 +3,24 @@ package mpb_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/vbauerster/mpb/v4"
+	"github.com/vbauerster/mpb/v4/decor"
 )
 
 func init() {
@@ -42,6 +53,51 @@ func TestBarCount(t *testing.T) {
 	p.Wait()
 }
 
+func TestAddBars(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	specs := make([]mpb.BarSpec, 50)
+	for i := range specs {
+		specs[i].Total = 10
+	}
+	bars := p.AddBars(specs)
+
+	if count := p.BarCount(); count != len(specs) {
+		t.Errorf("BarCount want: %d, got: %d\n", len(specs), count)
+	}
+	for i, bar := range bars {
+		if bar.ID() != i {
+			t.Errorf("Expected bar id: %d, got: %d\n", i, bar.ID())
+		}
+		bar.IncrBy(10)
+	}
+
+	p.Wait()
+}
+
+func TestBarByID(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	bars := make([]*mpb.Bar, 3)
+	for i := range bars {
+		bars[i] = p.AddBar(100, mpb.BarID(i+10))
+	}
+
+	for i, b := range bars {
+		if got := p.BarByID(i + 10); got != b {
+			t.Errorf("BarByID(%d) returned wrong bar\n", i+10)
+		}
+	}
+	if got := p.BarByID(42); got != nil {
+		t.Error("BarByID(42) expected nil")
+	}
+
+	for _, b := range bars {
+		p.Abort(b, true)
+	}
+	p.Wait()
+}
+
 func TestBarAbort(t *testing.T) {
 	p := mpb.New(mpb.WithOutput(ioutil.Discard))
 
@@ -73,39 +129,1203 @@ func TestBarAbort(t *testing.T) {
 	p.Wait()
 }
 
-func TestWithContext(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	shutdown := make(chan struct{})
+func TestUpdateBarPriorityConcurrent(t *testing.T) {
 	p := mpb.New(
 		mpb.WithOutput(ioutil.Discard),
-		mpb.WithContext(ctx),
-		mpb.WithRefreshRate(50*time.Millisecond),
-		mpb.WithShutdownNotifier(shutdown),
+		mpb.WithRefreshRate(10*time.Millisecond),
 	)
 
-	total := 10000
-	numBars := 3
-	bars := make([]*mpb.Bar, 0, numBars)
-	for i := 0; i < numBars; i++ {
-		bar := p.AddBar(int64(total))
-		bars = append(bars, bar)
+	runner := p.AddBar(10, mpb.BarRemoveOnComplete())
+	waiting := p.AddBar(10, mpb.BarReplaceOnComplete(runner))
+	// waiting bar isn't in the heap yet, priority applies once it's in
+	p.UpdateBarPriority(waiting, -1)
+
+	var wg sync.WaitGroup
+	bars := []*mpb.Bar{runner}
+	for i := 0; i < 4; i++ {
+		bars = append(bars, p.AddBar(100))
+	}
+	for _, b := range bars {
+		wg.Add(1)
+		go func(b *mpb.Bar) {
+			defer wg.Done()
+			for !b.Completed() {
+				p.UpdateBarPriority(bars[rand.Intn(len(bars))], rand.Intn(10))
+				b.Increment()
+				time.Sleep(randomDuration(5 * time.Millisecond))
+			}
+		}(b)
+	}
+	wg.Wait()
+	// let runner's removal promote waiting bar
+	time.Sleep(50 * time.Millisecond)
+
+	var top *mpb.Bar
+	p.ForEachBar(func(b *mpb.Bar) {
+		if top == nil {
+			top = b
+		}
+	})
+	if top != waiting {
+		t.Error("Expected waiting bar on top")
+	}
+
+	waiting.IncrBy(10)
+	p.Wait()
+}
+
+func TestAbortGroup(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	other := p.AddBar(100)
+	first := p.AddBar(100, mpb.BarGroup("job"), mpb.BarRemoveOnComplete())
+	p.AddBar(100, mpb.BarGroup("job"), mpb.BarReplaceOnComplete(first))
+	p.AddBar(100, mpb.BarGroup("job"))
+
+	p.AbortGroup("job", true)
+
+	if count := p.BarCount(); count != 1 {
+		t.Errorf("BarCount want: %d, got: %d\n", 1, count)
+	}
+
+	other.IncrBy(100)
+	p.Wait()
+}
+
+type eventRecorder struct {
+	sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) record(kind string, id int) {
+	r.Lock()
+	r.events = append(r.events, fmt.Sprintf("%s %d", kind, id))
+	r.Unlock()
+}
+
+func (r *eventRecorder) OnAdd(id int)      { r.record("add", id) }
+func (r *eventRecorder) OnComplete(id int) { r.record("complete", id) }
+func (r *eventRecorder) OnAbort(id int)    { r.record("abort", id) }
+
+func TestWithEventSink(t *testing.T) {
+	sink := new(eventRecorder)
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithEventSink(sink),
+	)
+
+	bar := p.AddBar(100)
+	aborted := p.AddBar(100)
+
+	p.Abort(aborted, false)
+	p.Abort(aborted, false)
+	bar.IncrBy(100)
+	aborted.IncrBy(100)
+	p.Wait()
+
+	got := strings.Join(sink.events, ", ")
+	want := "add 0, add 1, abort 1, complete 0"
+	if got != want {
+		t.Errorf("Want events: %q, got: %q\n", want, got)
+	}
+}
+
+func TestAddAfterWait(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+	p.Wait()
+
+	bar := p.AddBar(100)
+	if bar == nil {
+		t.Fatal("expected dead bar, got nil")
+	}
+
+	r := bar.ProxyReader(strings.NewReader("data"))
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Errorf("Unexpected error: %v\n", err)
+	}
+	bar.IncrBy(10)
+	bar.SetTotal(200, true)
+	p.Abort(bar, true)
+
+	if !bar.Completed() {
+		t.Error("expected dead bar to report completed")
+	}
+	if got := bar.Current(); got != 0 {
+		t.Errorf("Expected current: 0, got: %d\n", got)
+	}
+}
+
+func TestBarAbortDuringRender(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	var wg sync.WaitGroup
+	var waiting []*mpb.Bar
+	bars := make([]*mpb.Bar, 20)
+	for i := range bars {
+		b := p.AddBar(100)
+		if i%4 == 0 {
+			// waits for its turn, so Abort may hit not yet rendered bar
+			waiting = append(waiting, p.AddBar(100, mpb.BarReplaceOnComplete(b)))
+		}
+		bars[i] = b
+		wg.Add(1)
 		go func() {
-			for !bar.Completed() {
-				bar.Increment()
-				time.Sleep(randomDuration(100 * time.Millisecond))
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				b.Increment()
+				time.Sleep(randomDuration(2 * time.Millisecond))
 			}
 		}()
 	}
 
+	all := append(bars, waiting...)
+	for i := 0; i < 50; i++ {
+		b := all[rand.Intn(len(all))]
+		go p.Abort(b, rand.Intn(2) == 0)
+		time.Sleep(randomDuration(2 * time.Millisecond))
+	}
+
+	wg.Wait()
+	for _, b := range all {
+		p.Abort(b, true)
+	}
+	p.Wait()
+}
+
+func TestWithBarComparator(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithBarComparator(func(a, b *mpb.Bar) bool {
+			return a.ID() > b.ID()
+		}),
+	)
+
+	bars := make([]*mpb.Bar, 4)
+	for i := range bars {
+		bars[i] = p.AddBar(100, mpb.BarPriority(i))
+	}
+
+	var got []int
+	p.ForEachBar(func(b *mpb.Bar) { got = append(got, b.ID()) })
+	want := []int{3, 2, 1, 0}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Want order: %v, got: %v\n", want, got)
+	}
+
+	for _, b := range bars {
+		b.IncrBy(100)
+	}
+	p.Wait()
+}
+
+func barOrder(p *mpb.Progress) string {
+	var ids []int
+	p.ForEachBar(func(b *mpb.Bar) { ids = append(ids, b.ID()) })
+	return fmt.Sprint(ids)
+}
+
+func TestWithSortBars(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithSortBars(mpb.SortMostProgressedFirst),
+	)
+
+	bars := make([]*mpb.Bar, 3)
+	for i, n := range []int{10, 50, 30} {
+		bars[i] = p.AddBar(100)
+		bars[i].IncrBy(n)
+	}
 	time.Sleep(50 * time.Millisecond)
-	cancel()
 
+	if got, want := barOrder(p), "[1 2 0]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	bars[0].IncrBy(50)
+	time.Sleep(50 * time.Millisecond)
+
+	if got, want := barOrder(p), "[0 1 2]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	for _, b := range bars {
+		b.IncrBy(100)
+	}
+	p.Wait()
+}
+
+func TestWithSortBarsPriorityUpdate(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithSortBars(mpb.SortIncompleteFirst),
+	)
+
+	// all bars are incomplete, so order is up to priorities
+	bars := make([]*mpb.Bar, 3)
+	for i, priority := range []int{10, 20, 30} {
+		bars[i] = p.AddBar(100, mpb.BarPriority(priority))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if got, want := barOrder(p), "[0 1 2]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	// sorting must not have reassigned priorities
+	p.UpdateBarPriority(bars[2], 15)
+	time.Sleep(50 * time.Millisecond)
+
+	if got, want := barOrder(p), "[0 2 1]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	bars[0].IncrBy(100)
+	time.Sleep(50 * time.Millisecond)
+
+	if got, want := barOrder(p), "[2 1 0]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	for _, b := range bars {
+		b.IncrBy(100)
+	}
+	p.Wait()
+}
+
+func TestBarPin(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	bars := make([]*mpb.Bar, 3)
+	for i := range bars {
+		bars[i] = p.AddBar(100)
+	}
+	order := func() (ids []int) {
+		// let pin state reach the container
+		time.Sleep(50 * time.Millisecond)
+		p.ForEachBar(func(b *mpb.Bar) { ids = append(ids, b.ID()) })
+		return ids
+	}
+
+	bars[1].Pin()
+	bars[2].Pin()
+	if got, want := fmt.Sprint(order()), "[2 1 0]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	bars[1].Unpin()
+	bars[2].Unpin()
+	if got, want := fmt.Sprint(order()), "[0 1 2]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	for _, b := range bars {
+		b.IncrBy(100)
+	}
+	p.Wait()
+}
+
+func TestBarPinUpdatePriority(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	bars := make([]*mpb.Bar, 3)
+	for i := range bars {
+		bars[i] = p.AddBar(100)
+	}
+	order := func() (ids []int) {
+		// let pin state reach the container
+		time.Sleep(50 * time.Millisecond)
+		p.ForEachBar(func(b *mpb.Bar) { ids = append(ids, b.ID()) })
+		return ids
+	}
+
+	bars[0].Pin()
+	if got, want := fmt.Sprint(order()), "[0 1 2]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	p.UpdateBarPriority(bars[0], 10)
+	if got, want := fmt.Sprint(order()), "[0 1 2]"; got != want {
+		t.Errorf("Want pinned order: %s, got: %s\n", want, got)
+	}
+
+	bars[0].Unpin()
+	if got, want := fmt.Sprint(order()), "[1 2 0]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+
+	for _, b := range bars {
+		b.IncrBy(100)
+	}
+	p.Wait()
+}
+
+func TestAddPercentageBar(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.WithOutput(&buf), mpb.WithWidth(40))
+	bar := p.AddPercentageBar(200, mpb.PrependDecorators(decor.Name("file")))
+	bar.IncrBy(100)
+
+	frame, err := p.RenderFrame()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	got := strings.TrimSuffix(string(frame), "\n")
+	if !strings.HasPrefix(got, "file [") || !strings.HasSuffix(got, "]  50 %") {
+		t.Errorf("Unexpected frame: %q\n", got)
+	}
+
+	bar.IncrBy(100)
 	p.Wait()
+
+	if got := string(getLastLine(buf.Bytes())); !strings.HasSuffix(got, "] 100 %") {
+		t.Errorf("Unexpected last frame: %q\n", got)
+	}
+}
+
+func TestProgressDone(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+	bar := p.AddBar(10)
+
 	select {
-	case <-shutdown:
-	case <-time.After(100 * time.Millisecond):
-		t.Error("Progress didn't stop")
+	case <-p.Done():
+		t.Fatal("Done is closed before Wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	go p.Wait()
+	bar.IncrBy(10)
+
+	select {
+	case <-p.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("Done isn't closed after all bars have quit")
+	}
+}
+
+func TestForEachBar(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	bars := make([]*mpb.Bar, 3)
+	for i := range bars {
+		bars[i] = p.AddBar(100)
+	}
+	p.UpdateBarPriority(bars[0], 5)
+
+	var ids []int
+	var current int64
+	p.ForEachBar(func(b *mpb.Bar) {
+		ids = append(ids, b.ID())
+		// bar's own methods are fine to call
+		current += b.Current()
+	})
+	if got, want := fmt.Sprint(ids), "[1 2 0]"; got != want {
+		t.Errorf("Want order: %s, got: %s\n", want, got)
+	}
+	if current != 0 {
+		t.Errorf("Want zero current, got: %d\n", current)
+	}
+
+	for _, b := range bars {
+		b.IncrBy(100)
+	}
+	p.Wait()
+
+	var called bool
+	p.ForEachBar(func(*mpb.Bar) { called = true })
+	if called {
+		t.Error("fn has been called after quit")
+	}
+}
+
+func TestRefreshRateAndWidth(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(50*time.Millisecond),
+		mpb.WithWidth(40),
+	)
+
+	if got, want := p.RefreshRate(), 50*time.Millisecond; got != want {
+		t.Errorf("Want refresh rate: %v, got: %v\n", want, got)
+	}
+	if got, want := p.Width(), 40; got != want {
+		t.Errorf("Want width: %d, got: %d\n", want, got)
+	}
+
+	p.Wait()
+
+	if got := p.RefreshRate(); got != 0 {
+		t.Errorf("Want zero refresh rate after quit, got: %v\n", got)
+	}
+	if got := p.Width(); got != 0 {
+		t.Errorf("Want zero width after quit, got: %d\n", got)
+	}
+}
+
+func TestRenderFrame(t *testing.T) {
+	p := mpb.New(mpb.WithOutput(ioutil.Discard), mpb.WithWidth(40))
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Name("frame")))
+	bar.IncrBy(50)
+
+	frame, err := p.RenderFrame()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n", err)
+	}
+	if !bytes.HasPrefix(frame, []byte("frame")) {
+		t.Errorf("Expected frame to start with bar's name, got: %q\n", frame)
+	}
+	if bytes.ContainsRune(frame, '\x1b') {
+		t.Errorf("Unexpected control sequence in frame: %q\n", frame)
+	}
+
+	bar.IncrBy(50)
+	p.Wait()
+
+	if _, err := p.RenderFrame(); err != mpb.ErrShutdown {
+		t.Errorf("Expected %v, got: %v\n", mpb.ErrShutdown, err)
+	}
+}
+
+func TestWithFrameHistory(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithFrameHistory(2),
+	)
+
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Name("history")))
+	bar.IncrBy(50)
+	time.Sleep(100 * time.Millisecond)
+
+	frames := p.LastFrames()
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got: %d\n", len(frames))
 	}
+	for _, frame := range frames {
+		if !strings.HasPrefix(frame, "history") {
+			t.Errorf("Unexpected frame: %q\n", frame)
+		}
+	}
+
+	bar.IncrBy(50)
+	p.Wait()
+}
+
+func TestOutput(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Name("bar")))
+	fmt.Fprintln(p.Output(), "banner")
+	fmt.Fprint(p.Output(), "partial")
+	time.Sleep(50 * time.Millisecond)
+
+	out := buf.String()
+	if !strings.Contains(out, "banner\nbar") {
+		t.Errorf("Expected banner above bar, got: %q\n", out)
+	}
+	if strings.Contains(out, "partial") {
+		t.Errorf("Unexpected incomplete line in output: %q\n", out)
+	}
+
+	bar.IncrBy(100)
+	p.Wait()
+
+	if _, err := p.Output().Write([]byte("late\n")); err != mpb.ErrShutdown {
+		t.Errorf("Expected %v, got: %v\n", mpb.ErrShutdown, err)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithPipeDelimiter("\x00"),
+	)
+
+	closed := p.Pipe()
+	if c, ok := closed.(io.Closer); ok {
+		c.Close()
+	}
+	r := p.Pipe()
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Name("piped")))
+	bar.IncrBy(100)
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := ioutil.ReadAll(r)
+		done <- data
+	}()
+	p.Wait()
+
+	data := <-done
+	frames := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	last := frames[len(frames)-1]
+	if !strings.HasPrefix(last, "piped") {
+		t.Errorf("Unexpected last frame: %q\n", last)
+	}
+}
+
+func TestWithOnShutdown(t *testing.T) {
+	var buf bytes.Buffer
+	var calls int
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithOnShutdown(func(w io.Writer) {
+			calls++
+			fmt.Fprintln(w, "Done: 1 file")
+		}),
+	)
+
+	bar := p.AddBar(10, mpb.PrependDecorators(decor.Name("bar")))
+	bar.IncrBy(10)
+	p.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got: %d\n", calls)
+	}
+	if out := buf.String(); !strings.HasSuffix(out, "] \nDone: 1 file\n") {
+		t.Errorf("Expected summary below bar, got: %q\n", out)
+	}
+}
+
+func TestWithForceSimpleOutput(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithForceSimpleOutput(),
+	)
+
+	bar := p.AddBar(100)
+	bar.IncrBy(50)
+	time.Sleep(50 * time.Millisecond)
+	bar.IncrBy(50)
+	p.Wait()
+
+	if out := buf.String(); strings.Contains(out, "\x1b[") {
+		t.Errorf("Unexpected escape sequence in %q\n", out)
+	}
+}
+
+func TestWithRenderDelay(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithRenderDelay(make(chan struct{})),
+	)
+
+	bar := p.AddBar(100)
+	time.Sleep(50 * time.Millisecond)
+	bar.IncrBy(100)
+	// would block forever, if bar couldn't complete while delayed
+	p.Wait()
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got: %q\n", buf.String())
+	}
+}
+
+func TestWithSkipUnchanged(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithSkipUnchanged(),
+		mpb.WithWidth(40),
+	)
+
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Name("idle")))
+	bar.IncrBy(50)
+	time.Sleep(50 * time.Millisecond)
+	n := buf.Len()
+	time.Sleep(50 * time.Millisecond)
+
+	if buf.Len() != n {
+		t.Errorf("Expected no output for unchanged bar, got: %q\n", buf.String()[n:])
+	}
+
+	bar.IncrBy(50)
+	p.Wait()
+
+	if buf.Len() == n {
+		t.Error("Changed bar has not been written")
+	}
+}
+
+func TestWithTrimSpace(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(mpb.WithOutput(&buf), mpb.WithWidth(10), mpb.WithTrimSpace())
+
+	trimmed := p.AddBar(10)
+	spaced := p.AddBar(10, mpb.BarTrimSpace(false))
+	trimmed.IncrBy(10)
+	spaced.IncrBy(10)
+	p.Wait()
+
+	// both take the same width, spaces included
+	out := buf.String()
+	if !strings.HasSuffix(out, "[========]\n [======] \n") {
+		t.Errorf("Unexpected output: %q\n", out)
+	}
+}
+
+func TestWithTerminalWidthFunc(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithTerminalWidthFunc(func() (int, error) { return 12, nil }),
+	)
+
+	bar := p.AddBar(100)
+	bar.IncrBy(100)
+	p.Wait()
+
+	want := "\x1b[J [========] "
+	if got := string(getLastLine(buf.Bytes())); !strings.HasSuffix(got, want) {
+		t.Errorf("Expected bar of width 12, got: %q\n", got)
+	}
+}
+
+func TestWithFallbackWidthUnlimited(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithWidth(40),
+		mpb.WithFallbackWidth(0),
+	)
+
+	name := strings.Repeat("x", 100)
+	bar := p.AddBar(100, mpb.PrependDecorators(decor.Name(name)))
+	bar.IncrBy(100)
+
+	p.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	last := lines[len(lines)-1]
+	if !strings.HasSuffix(last, "] ") || utf8.RuneCountInString(last) < len(name)+40 {
+		t.Errorf("Expected bar at full width, got: %q\n", last)
+	}
+}
+
+func TestAddRacingWaitNoLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 50; i++ {
+		p := mpb.New(
+			mpb.WithOutput(ioutil.Discard),
+			mpb.WithRefreshRate(10*time.Millisecond),
+		)
+		bar := p.AddBar(1)
+		started := make(chan struct{})
+		go func() {
+			close(started)
+			p.AddBar(1).Increment()
+		}()
+		<-started
+		bar.Increment()
+		p.Wait()
+	}
+
+	// exiting goroutines need a moment to be accounted
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Goroutines leaked, before: %d, after: %d\n", before, after)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	shutdown := make(chan struct{})
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithContext(ctx),
+		mpb.WithRefreshRate(50*time.Millisecond),
+		mpb.WithShutdownNotifier(shutdown),
+	)
+
+	total := 10000
+	numBars := 3
+	bars := make([]*mpb.Bar, 0, numBars)
+	for i := 0; i < numBars; i++ {
+		bar := p.AddBar(int64(total))
+		bars = append(bars, bar)
+		go func() {
+			for !bar.Completed() {
+				bar.Increment()
+				time.Sleep(randomDuration(100 * time.Millisecond))
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	p.Wait()
+	select {
+	case <-shutdown:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("Progress didn't stop")
+	}
+}
+
+func TestWithMetricsHook(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[int][][2]int64)
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithMetricsHook(func(id int, current, total int64) {
+			mu.Lock()
+			calls[id] = append(calls[id], [2]int64{current, total})
+			mu.Unlock()
+		}),
+	)
+
+	totals := []int64{40, 80}
+	bars := make([]*mpb.Bar, len(totals))
+	for i, total := range totals {
+		bars[i] = p.AddBar(total)
+	}
+	for i := 0; i < 40; i++ {
+		bars[0].Increment()
+		bars[1].IncrBy(2)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	p.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, total := range totals {
+		frames := calls[i]
+		if len(frames) < 2 {
+			t.Fatalf("bar %d: expected hook called on each refresh, got: %d\n", i, len(frames))
+		}
+		var last int64
+		for _, f := range frames {
+			if f[0] < last || f[0] > total || f[1] != total {
+				t.Fatalf("bar %d: unexpected frame values: %v\n", i, frames)
+			}
+			last = f[0]
+		}
+		if last != total {
+			t.Errorf("bar %d: expected final current: %d, got: %d\n", i, total, last)
+		}
+	}
+}
+
+func TestWithJSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithJSONOutput(&buf),
+	)
+
+	total := 50
+	bar := p.AddBar(int64(total), mpb.BarID(7))
+	for i := 0; i < total; i++ {
+		bar.Increment()
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	p.Wait()
+
+	var last mpb.BarEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		if err := dec.Decode(&last); err != nil {
+			t.Fatalf("Decode: %v\n", err)
+		}
+	}
+
+	if last.ID != 7 || last.Current != int64(total) || last.Percent != 100 {
+		t.Errorf("Unexpected last event: %+v\n", last)
+	}
+}
+
+func TestWithJSONOutputDynamic(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithJSONOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	bar := p.AddBar(0)
+	for i := 0; i < 5; i++ {
+		bar.IncrBy(10)
+		time.Sleep(20 * time.Millisecond)
+	}
+	bar.SetTotal(50, true)
+
+	p.Wait()
+
+	var dynamic int
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e map[string]interface{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("Decode: %v\n", err)
+		}
+		if e["dynamic"] != true {
+			if e["total"] != float64(50) {
+				t.Errorf("Unexpected final bar event: %v\n", e)
+			}
+			continue
+		}
+		dynamic++
+		if e["total"] != float64(0) {
+			t.Errorf("Unexpected total in dynamic bar event: %v\n", e)
+		}
+		if _, ok := e["percent"]; ok {
+			t.Errorf("Unexpected percent in dynamic bar event: %v\n", e)
+		}
+		if _, ok := e["eta"]; ok {
+			t.Errorf("Unexpected eta in dynamic bar event: %v\n", e)
+		}
+	}
+
+	if dynamic == 0 {
+		t.Error("No dynamic bar event has been emitted")
+	}
+}
+
+func TestWithJSONOutputError(t *testing.T) {
+	wantErr := errors.New("disk full")
+	var gotErr error
+	var shutdown bool
+	p := mpb.New(
+		mpb.WithOutput(ioutil.Discard),
+		mpb.WithJSONOutput(errWriter{wantErr}),
+		mpb.WithErrorHandler(func(err error) { gotErr = err }),
+		mpb.WithOnShutdown(func(io.Writer) { shutdown = true }),
+	)
+
+	bar := p.AddBar(10)
+	bar.IncrBy(10)
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Wait hangs on json encode error")
+	}
+
+	if gotErr != wantErr {
+		t.Errorf("Want error: %v, got: %v\n", wantErr, gotErr)
+	}
+	if !shutdown {
+		t.Error("OnShutdown hook has been skipped")
+	}
+}
+
+func TestWithMaxFPS(t *testing.T) {
+	refreshes := func(options ...mpb.ContainerOption) (int, time.Duration) {
+		var buf bytes.Buffer
+		options = append(options,
+			mpb.WithOutput(&buf),
+			mpb.WithRefreshRate(10*time.Millisecond),
+		)
+		start := time.Now()
+		p := mpb.New(options...)
+		bar := p.AddBar(50)
+		for i := 0; i < 50; i++ {
+			bar.Increment()
+			time.Sleep(10 * time.Millisecond)
+		}
+		p.Wait()
+		// each refresh but the first one clears previous frame
+		clears := regexp.MustCompile("\x1b\\[\\d+A").FindAllString(buf.String(), -1)
+		return len(clears), time.Since(start)
+	}
+
+	unlimited, _ := refreshes()
+	limited, elapsed := refreshes(mpb.WithMaxFPS(5))
+
+	// one frame per 200ms, plus the first and the final ones
+	if max := int(elapsed/(200*time.Millisecond)) + 2; limited > max {
+		t.Errorf("Expected at most %d refreshes at 5 fps, got: %d\n", max, limited)
+	}
+	if limited >= unlimited {
+		t.Errorf("Max fps hasn't reduced refreshes: %d vs %d\n", limited, unlimited)
+	}
+}
+
+func TestWaitRendersFinalFrame(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithWidth(60),
+		mpb.WithRefreshRate(time.Second),
+	)
+
+	total := 60
+	bar := p.AddBar(int64(total), mpb.BarCompleteFill('#'), mpb.TrimSpace())
+	bar.IncrBy(total)
+
+	p.Wait()
+
+	wantBar := "[" + strings.Repeat("#", total-2) + "]"
+	got := string(getLastLine(buf.Bytes()))
+	if !strings.HasSuffix(got, wantBar) {
+		t.Errorf("Want last frame: %q, got: %q\n", wantBar, got)
+	}
+}
+
+func TestWithOutputsPlain(t *testing.T) {
+	var term, log bytes.Buffer
+	p := mpb.New(mpb.WithOutputs(&term, mpb.PlainOutput(&log)))
+
+	total := 20
+	bar := p.AddBar(int64(total))
+	for i := 0; i < total; i++ {
+		bar.Increment()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p.Wait()
+
+	if !bytes.Contains(term.Bytes(), []byte("\x1b[")) {
+		t.Error("Expected escape sequences in primary output")
+	}
+	if bytes.ContainsAny(log.Bytes(), "\x1b\r") {
+		t.Errorf("Plain output contains control codes: %q\n", log.String())
+	}
+	if !bytes.HasSuffix(getLastLine(term.Bytes()), getLastLine(log.Bytes())) {
+		t.Errorf("Last frames differ: %q vs %q\n", getLastLine(term.Bytes()), getLastLine(log.Bytes()))
+	}
+}
+
+func TestWithErrorHandler(t *testing.T) {
+	wantErr := errors.New("broken pipe")
+	var gotErr error
+	p := mpb.New(
+		mpb.WithOutput(errWriter{wantErr}),
+		mpb.WithErrorHandler(func(err error) { gotErr = err }),
+	)
+
+	bar := p.AddBar(10)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	if gotErr != wantErr {
+		t.Errorf("Want error: %v, got: %v\n", wantErr, gotErr)
+	}
+}
+
+func TestWithTitleMultiLine(t *testing.T) {
+	var buf bytes.Buffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithWidth(20),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithTitle("Downloading\nfiles: 2"),
+	)
+
+	bars := []*mpb.Bar{p.AddBar(10), p.AddBar(10)}
+	for i := 0; i < 10; i++ {
+		for _, b := range bars {
+			b.Increment()
+		}
+		time.Sleep(15 * time.Millisecond)
+	}
+
+	p.Wait()
+
+	// each refresh clears 2 lines of title and 2 bars
+	clears := regexp.MustCompile("\x1b\\[(\\d+)A").FindAllStringSubmatch(buf.String(), -1)
+	if len(clears) < 3 {
+		t.Fatalf("Expected several refreshes, got: %d\n", len(clears))
+	}
+	for _, m := range clears {
+		if m[1] != "4" {
+			t.Fatalf("Expected 4 lines cleared, got: %s\n", m[1])
+		}
+	}
+	if !strings.Contains(buf.String(), "Downloading\nfiles: 2\n") {
+		t.Errorf("Title lines are missing: %q\n", buf.String())
+	}
+}
+
+func TestBrokenPipeStopsOutput(t *testing.T) {
+	var count int
+	p := mpb.New(
+		mpb.WithOutput(errWriter{&os.PathError{Op: "write", Path: "|1", Err: syscall.EPIPE}}),
+		mpb.WithRefreshRate(time.Millisecond),
+		mpb.WithErrorHandler(func(error) { count++ }),
+	)
+
+	bar := p.AddBar(10)
+	time.Sleep(50 * time.Millisecond)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	if count != 1 {
+		t.Errorf("Expected broken pipe reported once, got: %d\n", count)
+	}
+}
+
+func TestBrokenPipeKeepsExtraOutputs(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutputs(
+			errWriter{&os.PathError{Op: "write", Path: "|1", Err: syscall.EPIPE}},
+			&buf,
+		),
+		mpb.WithRefreshRate(10*time.Millisecond),
+		mpb.WithSkipUnchanged(),
+		mpb.WithErrorHandler(func(error) {}),
+	)
+
+	bar := p.AddBar(10, mpb.AppendDecorators(decor.CountersNoUnit("%d/%d")))
+	time.Sleep(50 * time.Millisecond)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	if got := buf.String(); !strings.Contains(got, "10/10") {
+		t.Errorf("Extra output stopped after broken pipe, got: %q\n", got)
+	}
+}
+
+func TestBrokenPipeWrapped(t *testing.T) {
+	var count int
+	p := mpb.New(
+		mpb.WithOutput(errWriter{fmt.Errorf("write output: %w", syscall.EPIPE)}),
+		mpb.WithRefreshRate(time.Millisecond),
+		mpb.WithErrorHandler(func(error) { count++ }),
+	)
+
+	bar := p.AddBar(10)
+	time.Sleep(50 * time.Millisecond)
+	bar.IncrBy(10)
+
+	p.Wait()
+
+	if count != 1 {
+		t.Errorf("Expected wrapped broken pipe reported once, got: %d\n", count)
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestHideShow(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	total := 100
+	bar := p.AddBar(int64(total))
+	bar.IncrBy(total / 2)
+	time.Sleep(50 * time.Millisecond)
+
+	p.Hide()
+	hiddenLen := buf.Len()
+	bar.IncrBy(total / 4)
+	time.Sleep(50 * time.Millisecond)
+	if buf.Len() != hiddenLen {
+		t.Error("Output changed while hidden")
+	}
+	p.Show()
+
+	bar.IncrBy(total / 4)
+	p.Wait()
+	if buf.Len() == hiddenLen {
+		t.Error("No output after show")
+	}
+}
+
+func TestWaitWhileHidden(t *testing.T) {
+	var buf safeBuffer
+	p := mpb.New(
+		mpb.WithOutput(&buf),
+		mpb.WithRefreshRate(10*time.Millisecond),
+	)
+
+	total := 100
+	bar := p.AddBar(int64(total))
+	bar.IncrBy(total / 2)
+	time.Sleep(50 * time.Millisecond)
+
+	p.Hide()
+	hiddenLen := buf.Len()
+	bar.IncrBy(total / 2)
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Wait hangs while hidden")
+	}
+
+	if !bar.Completed() {
+		t.Error("Bar isn't completed")
+	}
+	if buf.Len() != hiddenLen {
+		t.Error("Output changed while hidden")
+	}
+}
+
+type safeBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Len()
+}
+
+func (b *safeBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
 }
 
 func getLastLine(bb []byte) []byte {
@@ -1,6 +1,7 @@
 package mpb
 
 import (
+	"context"
 	"io"
 	"time"
 )
@@ -14,9 +15,29 @@ type proxyReader struct {
 
 func (pr *proxyReader) Read(p []byte) (n int, err error) {
 	n, err = pr.ReadCloser.Read(p)
+	// n is counted regardless of err, as reader may return last chunk
+	// of data along with io.EOF
 	if n > 0 {
 		pr.bar.IncrBy(n, time.Since(pr.iT))
 		pr.iT = time.Now()
 	}
+	if err == io.EOF {
+		pr.bar.completeOnEOF()
+	}
 	return
 }
+
+// ctxProxyReader is proxyReader, which stops reading once ctx is done
+type ctxProxyReader struct {
+	*proxyReader
+	ctx context.Context
+}
+
+func (pr *ctxProxyReader) Read(p []byte) (n int, err error) {
+	select {
+	case <-pr.ctx.Done():
+		return 0, pr.ctx.Err()
+	default:
+	}
+	return pr.proxyReader.Read(p)
+}
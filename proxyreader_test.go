@@ -1,10 +1,12 @@
 package mpb_test
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/vbauerster/mpb/v4"
 )
@@ -51,3 +53,66 @@ func TestProxyReader(t *testing.T) {
 		t.Errorf("Expected written: %d, got: %d\n", total, written)
 	}
 }
+
+func TestProxyReaderAutoCompleteOnEOF(t *testing.T) {
+
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(0, mpb.BarAutoCompleteOnEOF())
+
+	_, err := io.Copy(ioutil.Discard, bar.ProxyReader(strings.NewReader(content)))
+	if err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+
+	// would block forever, if bar isn't complete
+	p.Wait()
+
+	if current := bar.Current(); current != int64(len(content)) {
+		t.Errorf("Expected current: %d, got: %d\n", len(content), current)
+	}
+}
+
+func TestProxyReaderDataWithEOF(t *testing.T) {
+
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	bar := p.AddBar(0, mpb.BarAutoCompleteOnEOF())
+
+	// last chunk of data comes along with io.EOF
+	reader := iotest.DataErrReader(strings.NewReader(content))
+	_, err := io.Copy(ioutil.Discard, bar.ProxyReader(reader))
+	if err != nil {
+		t.Errorf("Error copying from reader: %+v\n", err)
+	}
+
+	p.Wait()
+
+	if current := bar.Current(); current != int64(len(content)) {
+		t.Errorf("Expected current: %d, got: %d\n", len(content), current)
+	}
+}
+
+func TestProxyReaderContext(t *testing.T) {
+
+	p := mpb.New(mpb.WithOutput(ioutil.Discard))
+
+	reader := &testReader{Reader: strings.NewReader(content)}
+
+	bar := p.AddBar(int64(len(content)), mpb.TrimSpace())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := io.Copy(ioutil.Discard, bar.ProxyReaderContext(ctx, reader))
+	if err != context.Canceled {
+		t.Errorf("Expected error: %v, got: %v\n", context.Canceled, err)
+	}
+
+	p.Abort(bar, true)
+	p.Wait()
+
+	if reader.called {
+		t.Error("Read called after context is done")
+	}
+}
@@ -20,16 +20,36 @@ const (
 
 var defaultSpinnerStyle = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
+// VerticalSpinnerStyle is a spinner style, which bounces block up and
+// down. Combined with SpinnerFullWidth it looks like a VU meter.
+var VerticalSpinnerStyle = []string{"▁", "▃", "▄", "▅", "▆", "▇", "▆", "▅", "▄", "▃"}
+
 type spinnerFiller struct {
 	frames    []string
 	count     uint
 	alignment SpinnerAlignment
+	fullWidth bool
+	doneFrame string
 }
 
 func (s *spinnerFiller) Fill(w io.Writer, width int, stat *decor.Statistics) {
 
-	frame := s.frames[s.count%uint(len(s.frames))]
-	frameWidth := utf8.RuneCountInString(frame)
+	var frame string
+	var frameWidth int
+
+	if stat.Completed && s.doneFrame != "" {
+		// animation stops, done frame is never repeated
+		frame = s.doneFrame
+		frameWidth = utf8.RuneCountInString(frame)
+	} else {
+		frame = s.frames[s.count%uint(len(s.frames))]
+		frameWidth = utf8.RuneCountInString(frame)
+		if s.fullWidth && frameWidth > 0 {
+			n := width / frameWidth
+			frame = strings.Repeat(frame, n)
+			frameWidth *= n
+		}
+	}
 
 	if width < frameWidth {
 		return